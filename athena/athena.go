@@ -1,7 +1,8 @@
 package athena
 
 import (
-	"fmt"
+	"context"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -15,80 +16,121 @@ type AWSAthena interface {
 	StartQueryExecution(input *athena.StartQueryExecutionInput) (*athena.StartQueryExecutionOutput, error)
 	GetQueryResultsPages(input *athena.GetQueryResultsInput, fn func(*athena.GetQueryResultsOutput, bool) bool) error
 	GetQueryResults(input *athena.GetQueryResultsInput) (*athena.GetQueryResultsOutput, error)
+	StopQueryExecution(input *athena.StopQueryExecutionInput) (*athena.StopQueryExecutionOutput, error)
+	CreateNamedQuery(input *athena.CreateNamedQueryInput) (*athena.CreateNamedQueryOutput, error)
+	GetNamedQuery(input *athena.GetNamedQueryInput) (*athena.GetNamedQueryOutput, error)
+	ListNamedQueries(input *athena.ListNamedQueriesInput) (*athena.ListNamedQueriesOutput, error)
+	BatchGetNamedQuery(input *athena.BatchGetNamedQueryInput) (*athena.BatchGetNamedQueryOutput, error)
+	DeleteNamedQuery(input *athena.DeleteNamedQueryInput) (*athena.DeleteNamedQueryOutput, error)
+	CreatePreparedStatement(input *athena.CreatePreparedStatementInput) (*athena.CreatePreparedStatementOutput, error)
+	GetPreparedStatement(input *athena.GetPreparedStatementInput) (*athena.GetPreparedStatementOutput, error)
+	ListPreparedStatements(input *athena.ListPreparedStatementsInput) (*athena.ListPreparedStatementsOutput, error)
+	DeletePreparedStatement(input *athena.DeletePreparedStatementInput) (*athena.DeletePreparedStatementOutput, error)
+	ListQueryExecutions(input *athena.ListQueryExecutionsInput) (*athena.ListQueryExecutionsOutput, error)
+	BatchGetQueryExecution(input *athena.BatchGetQueryExecutionInput) (*athena.BatchGetQueryExecutionOutput, error)
+	ListDatabases(input *athena.ListDatabasesInput) (*athena.ListDatabasesOutput, error)
+	ListTableMetadata(input *athena.ListTableMetadataInput) (*athena.ListTableMetadataOutput, error)
+	GetTableMetadata(input *athena.GetTableMetadataInput) (*athena.GetTableMetadataOutput, error)
 }
 
 // WrapperAthena is wrapper of aws athena
 type WrapperAthena interface {
 	CheckJobStatus(execID string) (*athena.GetQueryExecutionOutput, error)
-	ExecuteQuery(query string, dest string) (*athena.StartQueryExecutionOutput, error)
+	ExecuteQuery(query string, dest string, opts ...Option) (*athena.StartQueryExecutionOutput, error)
+	ExecuteQueryWithArgs(query string, dest string, args ...interface{}) (*athena.StartQueryExecutionOutput, error)
 	WaitJobStatus(jobID string, durationSecond int) (bool, error)
+	WaitJobStatusContext(ctx context.Context, jobID string, opts *WaitOptions) (bool, error)
+	WaitQueryStats(ctx context.Context, jobID string, opts *WaitOptions) (*QueryStats, error)
 	GetResults(jobID string, nextToken string, maxResutls int) (*athena.GetQueryResultsOutput, error)
+	// Deprecated: GetResultsPages sends every page into pageCh and closes it, blocking until each page
+	// is received. Use Rows instead, which yields the rows across the pages with a context.
 	GetResultsPages(jobID string, maxResults int, pageCh chan *athena.GetQueryResultsOutput) error
+	Rows(ctx context.Context, jobID string, maxResults int) *RowIterator
+	S3Rows(ctx context.Context, jobID string, client AWSS3, opts *S3ReadOptions) *RowIterator
+	RunQuery(ctx context.Context, query string, opts *RunOptions) (*QueryResult, error)
+	RunQueries(ctx context.Context, queries []string, opts *BatchOptions) []*BatchResult
+	AddPartitions(ctx context.Context, table string, partitions []Partition, opts *PartitionOptions) error
+	DropPartitions(ctx context.Context, table string, partitions []Partition, opts *PartitionOptions) error
+	ListPartitions(ctx context.Context, table string, opts *RunOptions) ([]Partition, error)
+	RepairTable(ctx context.Context, table string, opts *RunOptions) error
+	CreateNamedQuery(name string, description string, query string, opts ...Option) (string, error)
+	GetNamedQuery(id string) (*athena.NamedQuery, error)
+	ListNamedQueries(ctx context.Context, opts ...Option) ([]*athena.NamedQuery, error)
+	DeleteNamedQuery(id string) error
+	ExecuteNamedQuery(id string, dest string, args ...interface{}) (*athena.StartQueryExecutionOutput, error)
+	PrepareStatement(name string, query string, opts ...Option) error
+	GetPreparedStatement(name string, opts ...Option) (*athena.PreparedStatement, error)
+	ListPreparedStatements(ctx context.Context, opts ...Option) ([]*athena.PreparedStatementSummary, error)
+	DeletePreparedStatement(name string, opts ...Option) error
+	ExecuteStatement(name string, dest string, args ...interface{}) (*athena.StartQueryExecutionOutput, error)
+	ExecuteStatementWithOptions(
+		name string, dest string, args []interface{}, opts ...Option) (*athena.StartQueryExecutionOutput, error)
+	InvalidateCache(query string, opts ...Option) error
+	ListExecutions(ctx context.Context, filter *ExecutionFilter) ([]*ExecutionSummary, error)
+	StopQuery(jobID string) error
+	CancelAll(ctx context.Context) error
+	RunningJobs() []string
+	ListDatabases(ctx context.Context, opts ...Option) ([]string, error)
+	ListTables(ctx context.Context, database string, opts ...Option) ([]string, error)
+	GetTableSchema(database string, table string, opts ...Option) (*TableSchema, error)
 }
 
 type wrapperAthena struct {
 	Client       AWSAthena
 	DatabaseName string
+	Options      []Option
+
+	mu            sync.Mutex
+	pendingCaches map[string]pendingCache
+	runningJobs   map[string]trackedExecution
+	reportedJobs  map[string]time.Time
 }
 
-// New is return new AthenaClient
-func New(client AWSAthena, dbName string) WrapperAthena {
+// New is return new AthenaClient. opts are the defaults of every query execution.
+func New(client AWSAthena, dbName string, opts ...Option) WrapperAthena {
 	return &wrapperAthena{
 		Client:       client,
 		DatabaseName: dbName,
+		Options:      opts,
 	}
 }
 
 func (s *wrapperAthena) CheckJobStatus(execID string) (*athena.GetQueryExecutionOutput, error) {
-	return s.Client.GetQueryExecution(&athena.GetQueryExecutionInput{
+	output, err := s.Client.GetQueryExecution(&athena.GetQueryExecutionInput{
 		QueryExecutionId: aws.String(execID),
 	})
+	if err != nil {
+		return nil, throttled("GetQueryExecution", err)
+	}
+	s.finishExecution(execID, output.QueryExecution)
+	return output, nil
 }
 
-func (s *wrapperAthena) ExecuteQuery(query string, dest string) (*athena.StartQueryExecutionOutput, error) {
-	input := &athena.StartQueryExecutionInput{
-		QueryExecutionContext: &athena.QueryExecutionContext{
-			Database: aws.String(s.DatabaseName),
-		},
-		QueryString: aws.String(query),
-		ResultConfiguration: &athena.ResultConfiguration{
-			OutputLocation: aws.String(dest),
-		},
+func (s *wrapperAthena) ExecuteQuery(query string, dest string, opts ...Option) (*athena.StartQueryExecutionOutput, error) {
+	conf := s.executionConfig(opts)
+	if entry, ok := conf.cachedExecution(query, dest); ok {
+		// the cached execution is not a new query, so its statistics are not reported again
+		s.markReported(entry.JobID)
+		return &athena.StartQueryExecutionOutput{
+			QueryExecutionId: aws.String(entry.JobID),
+		}, nil
+	}
+	output, err := s.Client.StartQueryExecution(conf.startQueryExecutionInput(query, dest))
+	if err != nil {
+		return nil, throttled("StartQueryExecution", err)
 	}
-	return s.Client.StartQueryExecution(input)
+	s.trackExecution(aws.StringValue(output.QueryExecutionId), conf)
+	s.rememberCacheKey(conf, aws.StringValue(output.QueryExecutionId), query, dest)
+	return output, nil
 }
 
 func (s *wrapperAthena) WaitJobStatus(jobID string, durationSecond int) (bool, error) {
-	for {
-		status, statusErr := s.CheckJobStatus(jobID)
-		if statusErr != nil {
-			return false, statusErr
-		}
-		// The state of query execution. QUEUED state is listed but is not used by Athena
-		// and is reserved for future use. RUNNING indicates that the query has been
-		// submitted to the service, and Athena will execute the query as soon as resources
-		// are available. SUCCEEDED indicates that the query completed without errors.
-		// FAILED indicates that the query experienced an error and did not complete
-		// processing. CANCELLED indicates that a user input interrupted query execution.
-		state := aws.StringValue(status.QueryExecution.Status.State)
-		fmt.Println(fmt.Sprintf("Wait job status=%s",state))
-		switch state {
-		case "SUCCEEDED":
-			return true, nil
-		case "RUNNING":
-			time.Sleep(time.Duration(durationSecond*1000) * time.Millisecond)
-			continue
-		case "CANCELLED":
-			message := fmt.Sprintf("Job is canceled jobId:%s", jobID)
-			return false, errors.New(message)
-		case "FAILED":
-			message := fmt.Sprintf("Job is failed jobId:%s", jobID)
-			return false, errors.New(message)
-		default:
-			message := fmt.Sprintf("Invalid state code jobId:%s", jobID)
-			return false, errors.New(message)
-		}
-	}
+	interval := time.Duration(durationSecond) * time.Second
+	return s.WaitJobStatusContext(context.Background(), jobID, &WaitOptions{
+		InitialInterval: interval,
+		MaxInterval:     interval,
+		Multiplier:      1,
+	})
 }
 
 func (s *wrapperAthena) GetResults(jobID string, nextToken string, maxResults int) (*athena.GetQueryResultsOutput, error) {
@@ -99,7 +141,11 @@ func (s *wrapperAthena) GetResults(jobID string, nextToken string, maxResults in
 	if nextToken != "" {
 		input.NextToken = aws.String(nextToken)
 	}
-	return s.Client.GetQueryResults(input)
+	output, err := s.Client.GetQueryResults(input)
+	if err != nil {
+		return nil, throttled("GetQueryResults", err)
+	}
+	return output, nil
 }
 
 func (s *wrapperAthena) GetResultsPages(
@@ -113,17 +159,11 @@ func (s *wrapperAthena) GetResultsPages(
 	}
 	err := s.Client.GetQueryResultsPages(params,
 		func(page *athena.GetQueryResultsOutput, lastPage bool) bool {
-			if lastPage {
-				fmt.Println("Reached last page")
-				close(pageCh)
-				return false
-			}
-			fmt.Println(fmt.Sprintf("Pass results to result channel nextToken=%s", *page.NextToken))
 			pageCh <- page
-			return true
+			return !lastPage
 		})
+	close(pageCh)
 	if err != nil {
-		close(pageCh)
 		return errors.Wrap(err, "Get query results pages failure")
 	}
 	return nil
@@ -1,8 +1,12 @@
 package athena
 
 import (
+	"fmt"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/athena"
+	"sort"
+	"strconv"
 	"testing"
 )
 
@@ -10,6 +14,17 @@ type AthenaMock struct{
 	GetQueryExecutionOutputCh chan *athena.GetQueryExecutionOutput
 	GetQueryResultsOutputCh chan *athena.GetQueryResultsOutput
 	GetQueryResultsOutputCounter int
+	StopQueryExecutionCounter int
+	ResultPages []*athena.GetQueryResultsOutput
+	StartQueryExecutionInput *athena.StartQueryExecutionInput
+	QueryStrings []string
+	NamedQueries map[string]*athena.NamedQuery
+	PreparedStatements map[string]*athena.PreparedStatement
+	Executions []*athena.QueryExecution
+	ListQueryExecutionsCounter int
+	Tables map[string][]*athena.TableMetadata
+	CatalogName string
+	StopQueryExecutionErr error
 }
 
 func (s *AthenaMock) GetQueryExecution(input *athena.GetQueryExecutionInput) (*athena.GetQueryExecutionOutput, error) {
@@ -18,6 +33,8 @@ func (s *AthenaMock) GetQueryExecution(input *athena.GetQueryExecutionInput) (*a
 
 func (s *AthenaMock) StartQueryExecution(
 	input *athena.StartQueryExecutionInput) (*athena.StartQueryExecutionOutput, error) {
+		s.StartQueryExecutionInput = input
+		s.QueryStrings = append(s.QueryStrings, aws.StringValue(input.QueryString))
 		return &athena.StartQueryExecutionOutput{
 			QueryExecutionId: aws.String("dummyQueryID"),
 		}, nil
@@ -26,6 +43,15 @@ func (s *AthenaMock) StartQueryExecution(
 
 func (s *AthenaMock) GetQueryResultsPages(
 	input *athena.GetQueryResultsInput, fn func(*athena.GetQueryResultsOutput, bool) bool) error {
+		if s.ResultPages != nil {
+			for i, page := range s.ResultPages {
+				s.GetQueryResultsOutputCounter++
+				if !fn(page, i == len(s.ResultPages)-1) {
+					break
+				}
+			}
+			return nil
+		}
 		isContinue := true
 		if s.GetQueryResultsOutputCounter > 0 {
 			isContinue = false
@@ -36,6 +62,10 @@ func (s *AthenaMock) GetQueryResultsPages(
 	}
 
 func (s *AthenaMock) GetQueryResults(input *athena.GetQueryResultsInput) (*athena.GetQueryResultsOutput, error) {
+	if s.ResultPages != nil {
+		index, _ := strconv.Atoi(aws.StringValue(input.NextToken))
+		return s.ResultPages[index], nil
+	}
 	return &athena.GetQueryResultsOutput{
 		NextToken: aws.String("dummyToken"),
 		ResultSet: &athena.ResultSet{
@@ -65,6 +95,185 @@ func (s *AthenaMock) GetQueryResults(input *athena.GetQueryResultsInput) (*athen
 	}, nil
 }
 
+func (s *AthenaMock) StopQueryExecution(
+	input *athena.StopQueryExecutionInput) (*athena.StopQueryExecutionOutput, error) {
+		s.StopQueryExecutionCounter++
+		if s.StopQueryExecutionErr != nil {
+			return nil, s.StopQueryExecutionErr
+		}
+		return &athena.StopQueryExecutionOutput{}, nil
+	}
+
+func (s *AthenaMock) CreateNamedQuery(
+	input *athena.CreateNamedQueryInput) (*athena.CreateNamedQueryOutput, error) {
+		if s.NamedQueries == nil {
+			s.NamedQueries = map[string]*athena.NamedQuery{}
+		}
+		id := fmt.Sprintf("dummyNamedQueryID%d", len(s.NamedQueries))
+		s.NamedQueries[id] = &athena.NamedQuery{
+			NamedQueryId: aws.String(id),
+			Name:         input.Name,
+			Database:     input.Database,
+			QueryString:  input.QueryString,
+			WorkGroup:    input.WorkGroup,
+		}
+		return &athena.CreateNamedQueryOutput{NamedQueryId: aws.String(id)}, nil
+	}
+
+func (s *AthenaMock) GetNamedQuery(input *athena.GetNamedQueryInput) (*athena.GetNamedQueryOutput, error) {
+	namedQuery, ok := s.NamedQueries[aws.StringValue(input.NamedQueryId)]
+	if !ok {
+		return nil, awserr.New(athena.ErrCodeInvalidRequestException, "dummy not found", nil)
+	}
+	return &athena.GetNamedQueryOutput{NamedQuery: namedQuery}, nil
+}
+
+// ListNamedQueries returns one id per page to exercise the paging
+func (s *AthenaMock) ListNamedQueries(input *athena.ListNamedQueriesInput) (*athena.ListNamedQueriesOutput, error) {
+	ids := []string{}
+	for id := range s.NamedQueries {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	index, _ := strconv.Atoi(aws.StringValue(input.NextToken))
+	output := &athena.ListNamedQueriesOutput{}
+	if index < len(ids) {
+		output.NamedQueryIds = []*string{aws.String(ids[index])}
+	}
+	if index+1 < len(ids) {
+		output.NextToken = aws.String(strconv.Itoa(index + 1))
+	}
+	return output, nil
+}
+
+func (s *AthenaMock) BatchGetNamedQuery(
+	input *athena.BatchGetNamedQueryInput) (*athena.BatchGetNamedQueryOutput, error) {
+		output := &athena.BatchGetNamedQueryOutput{}
+		for _, id := range input.NamedQueryIds {
+			output.NamedQueries = append(output.NamedQueries, s.NamedQueries[aws.StringValue(id)])
+		}
+		return output, nil
+	}
+
+func (s *AthenaMock) DeleteNamedQuery(input *athena.DeleteNamedQueryInput) (*athena.DeleteNamedQueryOutput, error) {
+	delete(s.NamedQueries, aws.StringValue(input.NamedQueryId))
+	return &athena.DeleteNamedQueryOutput{}, nil
+}
+
+func (s *AthenaMock) CreatePreparedStatement(
+	input *athena.CreatePreparedStatementInput) (*athena.CreatePreparedStatementOutput, error) {
+		if s.PreparedStatements == nil {
+			s.PreparedStatements = map[string]*athena.PreparedStatement{}
+		}
+		s.PreparedStatements[aws.StringValue(input.StatementName)] = &athena.PreparedStatement{
+			StatementName:  input.StatementName,
+			QueryStatement: input.QueryStatement,
+			WorkGroupName:  input.WorkGroup,
+		}
+		return &athena.CreatePreparedStatementOutput{}, nil
+	}
+
+func (s *AthenaMock) GetPreparedStatement(
+	input *athena.GetPreparedStatementInput) (*athena.GetPreparedStatementOutput, error) {
+		statement, ok := s.PreparedStatements[aws.StringValue(input.StatementName)]
+		if !ok {
+			return nil, awserr.New(athena.ErrCodeResourceNotFoundException, "dummy not found", nil)
+		}
+		return &athena.GetPreparedStatementOutput{PreparedStatement: statement}, nil
+	}
+
+func (s *AthenaMock) ListPreparedStatements(
+	input *athena.ListPreparedStatementsInput) (*athena.ListPreparedStatementsOutput, error) {
+		output := &athena.ListPreparedStatementsOutput{}
+		for _, statement := range s.PreparedStatements {
+			output.PreparedStatements = append(output.PreparedStatements, &athena.PreparedStatementSummary{
+				StatementName: statement.StatementName,
+			})
+		}
+		return output, nil
+	}
+
+func (s *AthenaMock) DeletePreparedStatement(
+	input *athena.DeletePreparedStatementInput) (*athena.DeletePreparedStatementOutput, error) {
+		delete(s.PreparedStatements, aws.StringValue(input.StatementName))
+		return &athena.DeletePreparedStatementOutput{}, nil
+	}
+
+// ListQueryExecutions returns the ids of Executions by pages of MaxResults
+func (s *AthenaMock) ListQueryExecutions(
+	input *athena.ListQueryExecutionsInput) (*athena.ListQueryExecutionsOutput, error) {
+		s.ListQueryExecutionsCounter++
+		start, _ := strconv.Atoi(aws.StringValue(input.NextToken))
+		end := start + int(aws.Int64Value(input.MaxResults))
+		if end > len(s.Executions) {
+			end = len(s.Executions)
+		}
+		output := &athena.ListQueryExecutionsOutput{}
+		for _, execution := range s.Executions[start:end] {
+			output.QueryExecutionIds = append(output.QueryExecutionIds, execution.QueryExecutionId)
+		}
+		if end < len(s.Executions) {
+			output.NextToken = aws.String(strconv.Itoa(end))
+		}
+		return output, nil
+	}
+
+func (s *AthenaMock) BatchGetQueryExecution(
+	input *athena.BatchGetQueryExecutionInput) (*athena.BatchGetQueryExecutionOutput, error) {
+		if len(input.QueryExecutionIds) > 50 {
+			return nil, awserr.New(athena.ErrCodeInvalidRequestException, "dummy too many ids", nil)
+		}
+		output := &athena.BatchGetQueryExecutionOutput{}
+		for _, id := range input.QueryExecutionIds {
+			for _, execution := range s.Executions {
+				if aws.StringValue(execution.QueryExecutionId) == aws.StringValue(id) {
+					output.QueryExecutions = append(output.QueryExecutions, execution)
+				}
+			}
+		}
+		return output, nil
+	}
+
+// ListDatabases returns one database of Tables per page to exercise the paging
+func (s *AthenaMock) ListDatabases(input *athena.ListDatabasesInput) (*athena.ListDatabasesOutput, error) {
+	s.CatalogName = aws.StringValue(input.CatalogName)
+	names := []string{}
+	for name := range s.Tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	index, _ := strconv.Atoi(aws.StringValue(input.NextToken))
+	output := &athena.ListDatabasesOutput{}
+	if index < len(names) {
+		output.DatabaseList = []*athena.Database{{Name: aws.String(names[index])}}
+	}
+	if index+1 < len(names) {
+		output.NextToken = aws.String(strconv.Itoa(index + 1))
+	}
+	return output, nil
+}
+
+func (s *AthenaMock) ListTableMetadata(
+	input *athena.ListTableMetadataInput) (*athena.ListTableMetadataOutput, error) {
+		s.CatalogName = aws.StringValue(input.CatalogName)
+		tables, ok := s.Tables[aws.StringValue(input.DatabaseName)]
+		if !ok {
+			return nil, awserr.New(athena.ErrCodeMetadataException, "dummy database not found", nil)
+		}
+		return &athena.ListTableMetadataOutput{TableMetadataList: tables}, nil
+	}
+
+func (s *AthenaMock) GetTableMetadata(
+	input *athena.GetTableMetadataInput) (*athena.GetTableMetadataOutput, error) {
+		s.CatalogName = aws.StringValue(input.CatalogName)
+		for _, table := range s.Tables[aws.StringValue(input.DatabaseName)] {
+			if aws.StringValue(table.Name) == aws.StringValue(input.TableName) {
+				return &athena.GetTableMetadataOutput{TableMetadata: table}, nil
+			}
+		}
+		return nil, awserr.New(athena.ErrCodeMetadataException, "dummy table not found", nil)
+	}
+
 func (s *AthenaMock) CloseCh() {
 	close(s.GetQueryExecutionOutputCh)
 	close(s.GetQueryResultsOutputCh)
@@ -233,15 +442,46 @@ func TestGetResultsPages(t *testing.T) {
 	}
 	athenaWrapper := New(athenaMock, "test-db")
 	pageCh := make(chan *athena.GetQueryResultsOutput)
-	athenaWrapper.GetResultsPages("dymmyJob", 1, pageCh)
+	go athenaWrapper.GetResultsPages("dymmyJob", 1, pageCh)
 
+	pages := 0
 	for page := range pageCh {
 		t.Logf("page's rows:%+v", page.ResultSet.Rows)
+		pages++
 	}
 
 	if athenaMock.GetQueryResultsOutputCounter != 1 {
 		t.Fatalf("Wrong athena mock counter %d", athenaMock.GetQueryResultsOutputCounter)
 	}
+	if pages != 1 {
+		t.Fatalf("The last page must be sent pages:%d", pages)
+	}
 
 	defer athenaMock.CloseCh()
+}
+
+func TestGetResultsPagesSendsLastPage(t *testing.T) {
+	athenaMock := &AthenaMock{
+		ResultPages: []*athena.GetQueryResultsOutput{
+			{NextToken: aws.String("1")},
+			{NextToken: aws.String("2")},
+			{},
+		},
+	}
+	athenaWrapper := New(athenaMock, "test-db")
+	pageCh := make(chan *athena.GetQueryResultsOutput, len(athenaMock.ResultPages))
+	if err := athenaWrapper.GetResultsPages("dymmyJob", 1, pageCh); err != nil {
+		t.Fatalf("Get results pages failure %s", err.Error())
+	}
+
+	pages := []*athena.GetQueryResultsOutput{}
+	for page := range pageCh {
+		pages = append(pages, page)
+	}
+	if len(pages) != 3 {
+		t.Fatalf("Wrong pages length:%d", len(pages))
+	}
+	if pages[2] != athenaMock.ResultPages[2] {
+		t.Fatalf("The last page must be sent %+v", pages[2])
+	}
 }
\ No newline at end of file
@@ -0,0 +1,116 @@
+package athena
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultConcurrency   = 5
+	defaultMaxRetries    = 5
+	defaultRetryInterval = 1 * time.Second
+	maxRetryInterval     = 30 * time.Second
+)
+
+// BatchOptions is options of RunQueries
+type BatchOptions struct {
+	RunOptions
+	// Concurrency is the number of queries running at once. Default is 5.
+	// Keep it under the active query quota of the account.
+	Concurrency int
+	// MaxRetries is the number of retries of starting a query throttled by TooManyRequestsException. Default is 5.
+	MaxRetries int
+	// RetryInterval is the first interval of the retries, which doubles on every retry. Default is 1 second.
+	RetryInterval time.Duration
+}
+
+// BatchResult is the result of one query of RunQueries
+type BatchResult struct {
+	Query  string
+	Result *QueryResult
+	Err    error
+}
+
+// RunQueries runs the queries with bounded concurrency, and returns the results in the order of the queries.
+// Cancelling ctx stops the queries which are running, and the queries not started yet fail with the error of ctx.
+func (s *wrapperAthena) RunQueries(ctx context.Context, queries []string, opts *BatchOptions) []*BatchResult {
+	if opts == nil {
+		opts = &BatchOptions{}
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	runOpts := opts.RunOptions
+	wait := runOpts.Wait.withDefaults()
+	wait.StopOnCancel = true
+	runOpts.Wait = &wait
+
+	results := make([]*BatchResult, len(queries))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, query := range queries {
+		result := &BatchResult{Query: query}
+		results[i] = result
+		if err := ctx.Err(); err != nil {
+			result.Err = err
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			result.Err = ctx.Err()
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			result.Result, result.Err = s.runQueryWithRetry(ctx, result.Query, &runOpts, opts)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// runQueryWithRetry runs the query, retrying the start while athena throttles it
+func (s *wrapperAthena) runQueryWithRetry(
+	ctx context.Context, query string, runOpts *RunOptions, opts *BatchOptions) (*QueryResult, error) {
+
+	maxRetries := opts.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = defaultMaxRetries
+	}
+	interval := opts.RetryInterval
+	if interval <= 0 {
+		interval = defaultRetryInterval
+	}
+
+	for attempt := 0; ; attempt++ {
+		started, err := s.ExecuteQuery(query, runOpts.OutputLocation, runOpts.Execution...)
+		if err == nil {
+			return s.awaitQueryResult(ctx, started, runOpts)
+		}
+		if !isThrottled(err) || attempt >= maxRetries {
+			return nil, errors.Wrap(err, fmt.Sprintf("Start query execution failure attempts=%d", attempt+1))
+		}
+
+		// Full jitter spreads the retries of the queries throttled at once
+		timer := time.NewTimer(time.Duration(rand.Int63n(int64(interval)) + 1))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, errors.Wrap(ctx.Err(), "Retry of query execution aborted")
+		case <-timer.C:
+		}
+		interval *= 2
+		if interval > maxRetryInterval {
+			interval = maxRetryInterval
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package athena
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/athena"
+)
+
+// batchAthenaMock throttles the first starts and counts the running queries
+type batchAthenaMock struct {
+	*AthenaMock
+	mu         sync.Mutex
+	throttles  int
+	started    int
+	running    int
+	maxRunning int
+}
+
+func (s *batchAthenaMock) StartQueryExecution(
+	input *athena.StartQueryExecutionInput) (*athena.StartQueryExecutionOutput, error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.throttles > 0 {
+		s.throttles--
+		return nil, awserr.New(athena.ErrCodeTooManyRequestsException, "dummy throttle", nil)
+	}
+	s.started++
+	s.running++
+	if s.running > s.maxRunning {
+		s.maxRunning = s.running
+	}
+	return &athena.StartQueryExecutionOutput{
+		QueryExecutionId: input.QueryString,
+	}, nil
+}
+
+func (s *batchAthenaMock) GetQueryExecution(
+	input *athena.GetQueryExecutionInput) (*athena.GetQueryExecutionOutput, error) {
+
+	time.Sleep(time.Millisecond)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running--
+	return &athena.GetQueryExecutionOutput{
+		QueryExecution: &athena.QueryExecution{
+			QueryExecutionId: input.QueryExecutionId,
+			Status: &athena.QueryExecutionStatus{
+				State: aws.String("SUCCEEDED"),
+			},
+		},
+	}, nil
+}
+
+func TestRunQueries(t *testing.T) {
+	athenaMock := &batchAthenaMock{AthenaMock: &AthenaMock{}, throttles: 3}
+	athenaWrapper := New(athenaMock, "test-db")
+
+	queries := []string{"q1", "q2", "q3", "q4", "q5", "q6"}
+	results := athenaWrapper.RunQueries(context.Background(), queries, &BatchOptions{
+		Concurrency:   2,
+		RetryInterval: time.Millisecond,
+	})
+	if len(results) != len(queries) {
+		t.Fatalf("Wrong results length:%d", len(results))
+	}
+	for i, result := range results {
+		if result.Err != nil {
+			t.Fatalf("Run query failure query=%s %s", result.Query, result.Err.Error())
+		}
+		if result.Query != queries[i] || result.Result.JobID != queries[i] {
+			t.Fatalf("Results must be in the order of queries index=%d result=%+v", i, result)
+		}
+	}
+	if athenaMock.started != len(queries) {
+		t.Fatalf("Wrong started counter %d", athenaMock.started)
+	}
+	if athenaMock.maxRunning > 2 {
+		t.Fatalf("Concurrency exceeded %d", athenaMock.maxRunning)
+	}
+}
+
+func TestRunQueriesRetryExhausted(t *testing.T) {
+	athenaMock := &batchAthenaMock{AthenaMock: &AthenaMock{}, throttles: 10}
+	athenaWrapper := New(athenaMock, "test-db")
+
+	results := athenaWrapper.RunQueries(context.Background(), []string{"q1"}, &BatchOptions{
+		MaxRetries:    2,
+		RetryInterval: time.Millisecond,
+	})
+	if results[0].Err == nil {
+		t.Fatalf("Throttled query must fail after retries")
+	}
+	if athenaMock.throttles != 7 {
+		t.Fatalf("Wrong number of attempts %d", 10-athenaMock.throttles)
+	}
+}
+
+func TestRunQueriesCancelled(t *testing.T) {
+	athenaMock := &batchAthenaMock{AthenaMock: &AthenaMock{}}
+	athenaWrapper := New(athenaMock, "test-db")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results := athenaWrapper.RunQueries(ctx, []string{"q1", "q2"}, nil)
+	for _, result := range results {
+		if result.Err != context.Canceled {
+			t.Fatalf("Wrong error %v", result.Err)
+		}
+	}
+	if athenaMock.started != 0 {
+		t.Fatalf("Cancelled batch must not start queries")
+	}
+}
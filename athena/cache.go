@@ -0,0 +1,369 @@
+package athena
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+	"github.com/pkg/errors"
+)
+
+const (
+	// pendingCacheTTL is how long the cache key of the started execution is kept for the wrapper to see it finish
+	pendingCacheTTL = 24 * time.Hour
+	// maxPendingCaches caps the cache keys of the started executions which the wrapper has not seen finish
+	maxPendingCaches = 1000
+)
+
+// CacheEntry is the execution of a succeeded query kept in ResultCache
+type CacheEntry struct {
+	JobID          string    `json:"job_id"`
+	OutputLocation string    `json:"output_location"`
+	CreatedAt      time.Time `json:"created_at"`
+	// ResultSettings is where and how the results are written, which a query must ask for to reuse them
+	ResultSettings string `json:"result_settings"`
+}
+
+// ResultCache stores the executions of succeeded queries keyed by the normalized queries
+type ResultCache interface {
+	Get(key string) (*CacheEntry, bool)
+	Set(key string, entry *CacheEntry) error
+	Delete(key string) error
+}
+
+// WithCache reuses the execution of the same query which succeeded within ttl instead of starting
+// a new one. A query is stored when the wrapper sees it succeed, e.g. by WaitJobStatus or RunQuery.
+// Only SELECT and WITH queries are cached, and DDL and DML statements always run. The cached execution
+// is reused only by the query which writes the results to the same dest with the same encryption and
+// expected bucket owner, and its statistics are not reported to the stats hook again.
+func WithCache(cache ResultCache, ttl time.Duration) Option {
+	return func(c *executionConfig) {
+		c.cache = cache
+		c.cacheTTL = ttl
+	}
+}
+
+// WithCacheBypass starts a new execution even if the cache has a fresh one.
+// The cache is still updated when the new execution succeeds.
+func WithCacheBypass() Option {
+	return func(c *executionConfig) {
+		c.cacheBypass = true
+	}
+}
+
+// NormalizeQuery returns the query without comments, redundant spaces and the trailing semicolon,
+// and in lower case except string literals and quoted identifiers
+func NormalizeQuery(query string) string {
+	var b strings.Builder
+	space := false
+	writeSpace := func() {
+		if space && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		space = false
+	}
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"':
+			end := i + 1
+			for end < len(query) {
+				if query[end] == c {
+					if end+1 < len(query) && query[end+1] == c {
+						end += 2
+						continue
+					}
+					break
+				}
+				end++
+			}
+			if end >= len(query) {
+				end = len(query) - 1
+			}
+			writeSpace()
+			b.WriteString(query[i : end+1])
+			i = end + 1
+		case strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i
+			}
+			space = true
+			i += end
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				end = len(query) - i - 4
+			}
+			space = true
+			i += end + 4
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			space = true
+			i++
+		default:
+			writeSpace()
+			if c >= 'A' && c <= 'Z' {
+				c += 'a' - 'A'
+			}
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return strings.TrimSuffix(strings.TrimSpace(b.String()), ";")
+}
+
+// cacheKey returns the key of the query which also depends on where the query runs
+func (c executionConfig) cacheKey(query string) string {
+	return strings.Join([]string{c.catalog, c.database, c.workGroup, NormalizeQuery(query)}, "\n")
+}
+
+// cacheable reports whether the query only reads, so that its results can be reused
+func cacheable(query string) bool {
+	normalized := strings.TrimLeft(NormalizeQuery(query), "( ")
+	for _, keyword := range []string{"select", "with"} {
+		if !strings.HasPrefix(normalized, keyword) {
+			continue
+		}
+		rest := normalized[len(keyword):]
+		if rest == "" || strings.IndexByte(" (*\"", rest[0]) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// resultSettings returns the destination, the encryption and the bucket owner of the results
+func (c executionConfig) resultSettings(dest string) string {
+	return strings.Join([]string{dest, c.encryptionOption, c.kmsKey, c.expectedBucketOwner}, "\n")
+}
+
+// cachedExecution returns the fresh cached execution of the query whose results are written to dest
+// in the same way as the config asks for
+func (c executionConfig) cachedExecution(query string, dest string) (*CacheEntry, bool) {
+	if c.cache == nil || c.cacheBypass || !cacheable(query) {
+		return nil, false
+	}
+	entry, ok := c.cache.Get(c.cacheKey(query))
+	if !ok || time.Since(entry.CreatedAt) > c.cacheTTL || entry.ResultSettings != c.resultSettings(dest) {
+		return nil, false
+	}
+	return entry, true
+}
+
+// pendingCache is the cache which stores the started execution when it succeeds
+type pendingCache struct {
+	cache          ResultCache
+	key            string
+	resultSettings string
+	startedAt      time.Time
+}
+
+// rememberCacheKey keeps the cache key of the started execution until the wrapper sees it finish.
+// The keys of the executions which are not seen finish within pendingCacheTTL are dropped, and the
+// oldest keys are dropped beyond maxPendingCaches, so those executions are just not cached.
+func (s *wrapperAthena) rememberCacheKey(conf executionConfig, jobID string, query string, dest string) {
+	if conf.cache == nil || !cacheable(query) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pendingCaches == nil {
+		s.pendingCaches = map[string]pendingCache{}
+	}
+	now := time.Now()
+	oldestJobID := ""
+	for pendingJobID, pending := range s.pendingCaches {
+		if now.Sub(pending.startedAt) > pendingCacheTTL {
+			delete(s.pendingCaches, pendingJobID)
+			continue
+		}
+		if oldestJobID == "" || pending.startedAt.Before(s.pendingCaches[oldestJobID].startedAt) {
+			oldestJobID = pendingJobID
+		}
+	}
+	if _, ok := s.pendingCaches[jobID]; !ok && len(s.pendingCaches) >= maxPendingCaches {
+		delete(s.pendingCaches, oldestJobID)
+	}
+	s.pendingCaches[jobID] = pendingCache{
+		cache:          conf.cache,
+		key:            conf.cacheKey(query),
+		resultSettings: conf.resultSettings(dest),
+		startedAt:      now,
+	}
+}
+
+// forgetCacheKey drops the cache key of the execution which is not going to succeed
+func (s *wrapperAthena) forgetCacheKey(jobID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.pendingCaches, jobID)
+}
+
+// storeCache stores the finished execution if it is succeeded and started with the cache
+func (s *wrapperAthena) storeCache(jobID string, execution *athena.QueryExecution) {
+	s.mu.Lock()
+	pending, ok := s.pendingCaches[jobID]
+	delete(s.pendingCaches, jobID)
+	s.mu.Unlock()
+
+	if !ok || aws.StringValue(execution.Status.State) != athena.QueryExecutionStateSucceeded {
+		return
+	}
+	entry := &CacheEntry{
+		JobID:          jobID,
+		CreatedAt:      aws.TimeValue(execution.Status.SubmissionDateTime),
+		ResultSettings: pending.resultSettings,
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	if execution.ResultConfiguration != nil {
+		entry.OutputLocation = aws.StringValue(execution.ResultConfiguration.OutputLocation)
+	}
+	// A failure of storing only loses the reuse, so it does not fail the query
+	pending.cache.Set(pending.key, entry)
+}
+
+func (s *wrapperAthena) InvalidateCache(query string, opts ...Option) error {
+	conf := s.executionConfig(opts)
+	if conf.cache == nil {
+		return nil
+	}
+	return conf.cache.Delete(conf.cacheKey(query))
+}
+
+// memoryCache is ResultCache which keeps the least recently used entries in memory
+type memoryCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List
+}
+
+type memoryCacheItem struct {
+	key   string
+	entry *CacheEntry
+}
+
+// NewMemoryCache returns in-memory LRU ResultCache which keeps capacity entries at most
+func NewMemoryCache(capacity int) ResultCache {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &memoryCache{
+		capacity: capacity,
+		entries:  map[string]*list.Element{},
+		order:    list.New(),
+	}
+}
+
+func (c *memoryCache) Get(key string) (*CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*memoryCacheItem).entry, true
+}
+
+func (c *memoryCache) Set(key string, entry *CacheEntry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*memoryCacheItem).entry = entry
+		c.order.MoveToFront(element)
+		return nil
+	}
+	c.entries[key] = c.order.PushFront(&memoryCacheItem{key: key, entry: entry})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryCacheItem).key)
+	}
+	return nil
+}
+
+func (c *memoryCache) Delete(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		c.order.Remove(element)
+		delete(c.entries, key)
+	}
+	return nil
+}
+
+// fileCache is ResultCache which keeps an entry per file in a directory
+type fileCache struct {
+	dir string
+}
+
+// NewFileCache returns ResultCache which keeps the entries as JSON files in dir
+func NewFileCache(dir string) (ResultCache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("Create cache directory failure dir=%s", dir))
+	}
+	return &fileCache{dir: dir}, nil
+}
+
+func (c *fileCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (c *fileCache) Get(key string) (*CacheEntry, bool) {
+	data, err := ioutil.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	entry := &CacheEntry{}
+	if err := json.Unmarshal(data, entry); err != nil {
+		return nil, false
+	}
+	return entry, true
+}
+
+func (c *fileCache) Set(key string, entry *CacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Wrap(err, "Json marshal failure")
+	}
+	// Write and rename so that readers never see a partial file
+	tmp, err := ioutil.TempFile(c.dir, "entry-")
+	if err != nil {
+		return errors.Wrap(err, "Create cache file failure")
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return errors.Wrap(err, "Write cache file failure")
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return errors.Wrap(err, "Close cache file failure")
+	}
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		os.Remove(tmp.Name())
+		return errors.Wrap(err, "Rename cache file failure")
+	}
+	return nil
+}
+
+func (c *fileCache) Delete(key string) error {
+	if err := os.Remove(c.path(key)); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "Remove cache file failure")
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+package athena
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestNormalizeQuery(t *testing.T) {
+	query := "SELECT  *\n\tFROM Logs -- comment\nWHERE name = 'Foo  Bar' /* block */ AND \"Col\" = 1;"
+	expected := "select * from logs where name = 'Foo  Bar' and \"Col\" = 1"
+	if normalized := NormalizeQuery(query); normalized != expected {
+		t.Fatalf("Wrong normalized query. Expected %s but %s", expected, normalized)
+	}
+}
+
+func TestExecuteQueryCache(t *testing.T) {
+	athenaMock := &AthenaMock{
+		GetQueryExecutionOutputCh: makeFinishedExecutionCh("SUCCEEDED", ""),
+	}
+	cache := NewMemoryCache(10)
+	athenaWrapper := New(athenaMock, "test-db", WithCache(cache, time.Hour))
+
+	if _, err := athenaWrapper.ExecuteQuery("SELECT 1", "s3://test-bucket"); err != nil {
+		t.Fatalf("Execute failure %s", err.Error())
+	}
+	if _, err := athenaWrapper.WaitJobStatusContext(context.Background(), "dummyQueryID", &WaitOptions{
+		InitialInterval: time.Millisecond,
+	}); err != nil {
+		t.Fatalf("Wait job status failure %s", err.Error())
+	}
+
+	result, err := athenaWrapper.ExecuteQuery("select   1;", "s3://test-bucket")
+	if err != nil {
+		t.Fatalf("Execute failure %s", err.Error())
+	}
+	if *result.QueryExecutionId != "dummyQueryID" || len(athenaMock.QueryStrings) != 1 {
+		t.Fatalf("Cached execution must be reused queries=%+v", athenaMock.QueryStrings)
+	}
+
+	if _, err := athenaWrapper.ExecuteQuery("SELECT 1", "s3://test-bucket", WithCacheBypass()); err != nil {
+		t.Fatalf("Execute failure %s", err.Error())
+	}
+	if len(athenaMock.QueryStrings) != 2 {
+		t.Fatalf("Bypass must start new execution queries=%+v", athenaMock.QueryStrings)
+	}
+
+	if _, err := athenaWrapper.ExecuteQuery("SELECT 1", "s3://test-bucket", WithDatabase("other-db")); err != nil {
+		t.Fatalf("Execute failure %s", err.Error())
+	}
+	if len(athenaMock.QueryStrings) != 3 {
+		t.Fatalf("Query in other database must not be reused queries=%+v", athenaMock.QueryStrings)
+	}
+
+	if err := athenaWrapper.InvalidateCache("SELECT 1"); err != nil {
+		t.Fatalf("Invalidate cache failure %s", err.Error())
+	}
+	if _, err := athenaWrapper.ExecuteQuery("SELECT 1", "s3://test-bucket"); err != nil {
+		t.Fatalf("Execute failure %s", err.Error())
+	}
+	if len(athenaMock.QueryStrings) != 4 {
+		t.Fatalf("Invalidated query must start new execution queries=%+v", athenaMock.QueryStrings)
+	}
+}
+
+func TestRunQueryCacheHitIsNotReported(t *testing.T) {
+	hooked := 0
+	athenaMock := &AthenaMock{
+		GetQueryExecutionOutputCh: makeStateCh("SUCCEEDED", "SUCCEEDED"),
+		ResultPages:               makeResultPages(makeColumns("id", "bigint"), []string{"1"}),
+	}
+	athenaWrapper := New(athenaMock, "test-db",
+		WithCache(NewMemoryCache(10), time.Hour),
+		WithStatsHook(func(stats *QueryStats) {
+			hooked++
+		}),
+	)
+	opts := &RunOptions{OutputLocation: "s3://test-bucket", Wait: &WaitOptions{InitialInterval: time.Millisecond}}
+	for i := 0; i < 2; i++ {
+		if _, err := athenaWrapper.RunQuery(context.Background(), "SELECT 1", opts); err != nil {
+			t.Fatalf("Run query failure %s", err.Error())
+		}
+	}
+	if len(athenaMock.QueryStrings) != 1 {
+		t.Fatalf("Cached execution must be reused queries=%+v", athenaMock.QueryStrings)
+	}
+	if hooked != 1 {
+		t.Fatalf("Cached execution must not be reported again but %d", hooked)
+	}
+}
+
+func TestExecuteQueryCacheResultSettings(t *testing.T) {
+	athenaMock := &AthenaMock{
+		GetQueryExecutionOutputCh: makeFinishedExecutionCh("SUCCEEDED", ""),
+	}
+	athenaWrapper := New(athenaMock, "test-db", WithCache(NewMemoryCache(10), time.Hour))
+
+	if _, err := athenaWrapper.ExecuteQuery("SELECT 1", "s3://test-bucket"); err != nil {
+		t.Fatalf("Execute failure %s", err.Error())
+	}
+	if _, err := athenaWrapper.WaitJobStatusContext(context.Background(), "dummyQueryID", &WaitOptions{
+		InitialInterval: time.Millisecond,
+	}); err != nil {
+		t.Fatalf("Wait job status failure %s", err.Error())
+	}
+
+	calls := []struct {
+		dest string
+		opts []Option
+	}{
+		{"s3://other-bucket", nil},
+		{"s3://test-bucket", []Option{WithEncryption("SSE_KMS", "test-key")}},
+		{"s3://test-bucket", []Option{WithExpectedBucketOwner("123456789012")}},
+	}
+	for i, call := range calls {
+		if _, err := athenaWrapper.ExecuteQuery("SELECT 1", call.dest, call.opts...); err != nil {
+			t.Fatalf("Execute failure %s", err.Error())
+		}
+		if len(athenaMock.QueryStrings) != i+2 {
+			t.Fatalf("Execution written elsewhere must not be reused dest=%s", call.dest)
+		}
+	}
+
+	if _, err := athenaWrapper.ExecuteQuery("SELECT 1", "s3://test-bucket"); err != nil {
+		t.Fatalf("Execute failure %s", err.Error())
+	}
+	if len(athenaMock.QueryStrings) != len(calls)+1 {
+		t.Fatalf("Execution written to the same dest must be reused queries=%+v", athenaMock.QueryStrings)
+	}
+}
+
+func TestExecuteQueryCacheSkipsStatements(t *testing.T) {
+	athenaMock := &AthenaMock{
+		GetQueryExecutionOutputCh: makeFinishedExecutionCh("SUCCEEDED", ""),
+	}
+	athenaWrapper := New(athenaMock, "test-db", WithCache(NewMemoryCache(10), time.Hour))
+
+	query := "ALTER TABLE logs ADD PARTITION (dt = '2020-01-01')"
+	if _, err := athenaWrapper.ExecuteQuery(query, "s3://test-bucket"); err != nil {
+		t.Fatalf("Execute failure %s", err.Error())
+	}
+	if _, err := athenaWrapper.WaitJobStatusContext(context.Background(), "dummyQueryID", &WaitOptions{
+		InitialInterval: time.Millisecond,
+	}); err != nil {
+		t.Fatalf("Wait job status failure %s", err.Error())
+	}
+	if _, err := athenaWrapper.ExecuteQuery(query, "s3://test-bucket"); err != nil {
+		t.Fatalf("Execute failure %s", err.Error())
+	}
+	if len(athenaMock.QueryStrings) != 2 {
+		t.Fatalf("Repeated DDL must start new execution queries=%+v", athenaMock.QueryStrings)
+	}
+
+	for query, expected := range map[string]bool{
+		"-- comment\nSELECT 1":                   true,
+		"(select * from logs)":                   true,
+		"WITH t AS (SELECT 1) SELECT * FROM t":   true,
+		"INSERT INTO logs SELECT * FROM staging": false,
+		"MSCK REPAIR TABLE logs":                 false,
+		"DROP TABLE logs":                        false,
+		"selected":                               false,
+	} {
+		if cacheable(query) != expected {
+			t.Fatalf("Wrong cacheable query=%s expected=%t", query, expected)
+		}
+	}
+}
+
+func TestPendingCachesLimit(t *testing.T) {
+	athenaWrapper := New(&AthenaMock{}, "test-db", WithCache(NewMemoryCache(10), time.Hour)).(*wrapperAthena)
+	athenaWrapper.pendingCaches = map[string]pendingCache{
+		"expiredQueryID": {key: "expired", startedAt: time.Now().Add(-2 * pendingCacheTTL)},
+	}
+	for i := 1; i < maxPendingCaches; i++ {
+		athenaWrapper.pendingCaches[fmt.Sprintf("pendingQueryID%d", i)] = pendingCache{
+			key:       "pending",
+			startedAt: time.Now().Add(-time.Duration(i) * time.Second),
+		}
+	}
+	athenaWrapper.pendingCaches["oldestQueryID"] = pendingCache{key: "oldest", startedAt: time.Now().Add(-time.Hour)}
+
+	if _, err := athenaWrapper.ExecuteQuery("SELECT 1", "s3://test-bucket"); err != nil {
+		t.Fatalf("Execute failure %s", err.Error())
+	}
+	if _, ok := athenaWrapper.pendingCaches["expiredQueryID"]; ok {
+		t.Fatalf("Expired pending cache must be dropped")
+	}
+	if _, ok := athenaWrapper.pendingCaches["oldestQueryID"]; ok {
+		t.Fatalf("Oldest pending cache must be dropped beyond the cap")
+	}
+	if _, ok := athenaWrapper.pendingCaches["dummyQueryID"]; !ok || len(athenaWrapper.pendingCaches) != maxPendingCaches {
+		t.Fatalf("Wrong pending caches length:%d", len(athenaWrapper.pendingCaches))
+	}
+}
+
+func TestMemoryCacheEviction(t *testing.T) {
+	cache := NewMemoryCache(2)
+	cache.Set("a", &CacheEntry{JobID: "a"})
+	cache.Set("b", &CacheEntry{JobID: "b"})
+	cache.Get("a")
+	cache.Set("c", &CacheEntry{JobID: "c"})
+	if _, ok := cache.Get("b"); ok {
+		t.Fatalf("Least recently used entry must be evicted")
+	}
+	if _, ok := cache.Get("a"); !ok {
+		t.Fatalf("Recently used entry must be kept")
+	}
+}
+
+func TestFileCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "athena-cache")
+	if err != nil {
+		t.Fatalf("Create temp dir failure %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	cache, err := NewFileCache(dir)
+	if err != nil {
+		t.Fatalf("New file cache failure %s", err.Error())
+	}
+	createdAt := time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC)
+	if err := cache.Set("key", &CacheEntry{JobID: "dummyID", CreatedAt: createdAt}); err != nil {
+		t.Fatalf("Set failure %s", err.Error())
+	}
+	entry, ok := cache.Get("key")
+	if !ok || entry.JobID != "dummyID" || !entry.CreatedAt.Equal(createdAt) {
+		t.Fatalf("Wrong entry %+v", entry)
+	}
+	if err := cache.Delete("key"); err != nil {
+		t.Fatalf("Delete failure %s", err.Error())
+	}
+	if _, ok := cache.Get("key"); ok {
+		t.Fatalf("Deleted entry must not be found")
+	}
+	if err := cache.Delete("key"); err != nil {
+		t.Fatalf("Deleting missing entry must not fail %s", err.Error())
+	}
+}
@@ -0,0 +1,402 @@
+package athena
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+	"github.com/pkg/errors"
+)
+
+const tagName = "athena"
+
+var dateLayouts = []string{"2006-01-02"}
+
+var timestampLayouts = []string{
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999 MST",
+	"2006-01-02 15:04:05.999999999 -07:00",
+	"2006-01-02T15:04:05.999999999Z07:00",
+}
+
+// ResultDecoder decodes rows of query results into go values by the column types of ResultSetMetadata.
+// A decoder is meant to be used for the pages of one query, because the header row is dropped
+// only from the first page.
+type ResultDecoder struct {
+	columns []*athena.ColumnInfo
+	started bool
+}
+
+// NewResultDecoder returns decoder for the pages of one query results
+func NewResultDecoder() *ResultDecoder {
+	return &ResultDecoder{}
+}
+
+// UnmarshalResults decodes the rows of single page of query results into out.
+// See ResultDecoder.Decode about out.
+func UnmarshalResults(page *athena.GetQueryResultsOutput, out interface{}) error {
+	return NewResultDecoder().Decode(page, out)
+}
+
+// Decode appends the rows of the page to out. out must be a pointer to a slice of
+// maps keyed by strings, structs or struct pointers. Struct fields are mapped by
+// `athena:"column"` tags, or by case insensitive field names when the tag is missing.
+// NULL values are decoded as nil or the zero value of the field.
+func (d *ResultDecoder) Decode(page *athena.GetQueryResultsOutput, out interface{}) error {
+	slice := reflect.ValueOf(out)
+	if slice.Kind() != reflect.Ptr || slice.IsNil() || slice.Elem().Kind() != reflect.Slice {
+		return errors.New(fmt.Sprintf("Decode destination must be a pointer to slice out=%T", out))
+	}
+	slice = slice.Elem()
+	rows := d.rows(page)
+
+	elemType := slice.Type().Elem()
+	for _, row := range rows {
+		elem := reflect.New(elemType).Elem()
+		if err := d.decodeInto(row, elem); err != nil {
+			return err
+		}
+		slice.Set(reflect.Append(slice, elem))
+	}
+	return nil
+}
+
+// rows returns data rows of the page without the header row
+func (d *ResultDecoder) rows(page *athena.GetQueryResultsOutput) []*athena.Row {
+	if page == nil || page.ResultSet == nil {
+		return nil
+	}
+	if page.ResultSet.ResultSetMetadata != nil && len(page.ResultSet.ResultSetMetadata.ColumnInfo) > 0 {
+		d.columns = page.ResultSet.ResultSetMetadata.ColumnInfo
+	}
+	rows := page.ResultSet.Rows
+	if !d.started {
+		d.started = true
+		if len(rows) > 0 && isHeaderRow(d.columns, rows[0]) {
+			rows = rows[1:]
+		}
+	}
+	return rows
+}
+
+func (d *ResultDecoder) decodeInto(row *athena.Row, elem reflect.Value) error {
+	switch {
+	case elem.Kind() == reflect.Map && elem.Type().Key().Kind() == reflect.String:
+		return mapRow(d.columns, row, elem)
+	case elem.Kind() == reflect.Struct:
+		return scanRow(d.columns, row, elem)
+	case elem.Kind() == reflect.Ptr && elem.Type().Elem().Kind() == reflect.Struct:
+		elem.Set(reflect.New(elem.Type().Elem()))
+		return scanRow(d.columns, row, elem.Elem())
+	}
+	return errors.New(fmt.Sprintf("Unsupported decode destination type=%s", elem.Type()))
+}
+
+// isHeaderRow reports whether the row is the header row which athena puts on the first page
+func isHeaderRow(columns []*athena.ColumnInfo, row *athena.Row) bool {
+	if len(columns) == 0 || len(columns) != len(row.Data) {
+		return false
+	}
+	for i, column := range columns {
+		value := row.Data[i].VarCharValue
+		if value == nil {
+			return false
+		}
+		if *value != aws.StringValue(column.Name) && *value != aws.StringValue(column.Label) {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeRow decodes the row into map which is keyed by column names
+func decodeRow(columns []*athena.ColumnInfo, row *athena.Row) (map[string]interface{}, error) {
+	record := make(map[string]interface{}, len(columns))
+	for i, column := range columns {
+		var raw *string
+		if i < len(row.Data) {
+			raw = row.Data[i].VarCharValue
+		}
+		value, err := parseValue(aws.StringValue(column.Type), raw)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("Decode column failure column=%s", aws.StringValue(column.Name)))
+		}
+		record[aws.StringValue(column.Name)] = value
+	}
+	return record, nil
+}
+
+// mapRow sets the values of the row into the map keyed by column names. The values are converted into
+// the element type of the map like the struct fields, e.g. map[string]string keeps the raw text.
+func mapRow(columns []*athena.ColumnInfo, row *athena.Row, dest reflect.Value) error {
+	if dest.Type().Elem().Kind() == reflect.Interface {
+		record, err := decodeRow(columns, row)
+		if err != nil {
+			return err
+		}
+		m := reflect.MakeMapWithSize(dest.Type(), len(record))
+		for name, value := range record {
+			elem := reflect.Zero(dest.Type().Elem())
+			if value != nil {
+				elem = reflect.ValueOf(value)
+			}
+			if !elem.Type().AssignableTo(dest.Type().Elem()) {
+				return errors.New(fmt.Sprintf("Cannot set value into map column=%s type=%s", name, dest.Type()))
+			}
+			m.SetMapIndex(reflect.ValueOf(name).Convert(dest.Type().Key()), elem)
+		}
+		dest.Set(m)
+		return nil
+	}
+
+	m := reflect.MakeMapWithSize(dest.Type(), len(columns))
+	for i, column := range columns {
+		name := aws.StringValue(column.Name)
+		var raw *string
+		if i < len(row.Data) {
+			raw = row.Data[i].VarCharValue
+		}
+		value, err := parseValue(aws.StringValue(column.Type), raw)
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("Decode column failure column=%s", name))
+		}
+		elem := reflect.New(dest.Type().Elem()).Elem()
+		if err := assignValue(elem, value, aws.StringValue(raw)); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("Set map value failure column=%s type=%s", name, dest.Type()))
+		}
+		m.SetMapIndex(reflect.ValueOf(name).Convert(dest.Type().Key()), elem)
+	}
+	dest.Set(m)
+	return nil
+}
+
+// scanRow sets the values of the row into the fields of the struct
+func scanRow(columns []*athena.ColumnInfo, row *athena.Row, dest reflect.Value) error {
+	fields := structFields(dest.Type())
+	for i, column := range columns {
+		name := aws.StringValue(column.Name)
+		index, ok := fields[strings.ToLower(name)]
+		if !ok {
+			continue
+		}
+		var raw *string
+		if i < len(row.Data) {
+			raw = row.Data[i].VarCharValue
+		}
+		value, err := parseValue(aws.StringValue(column.Type), raw)
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("Decode column failure column=%s", name))
+		}
+		if err := assignValue(dest.Field(index), value, aws.StringValue(raw)); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("Set field failure column=%s", name))
+		}
+	}
+	return nil
+}
+
+// structFields returns field indexes of the struct keyed by lower case column names
+func structFields(t reflect.Type) map[string]int {
+	fields := make(map[string]int, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := field.Name
+		if tag, ok := field.Tag.Lookup(tagName); ok {
+			tag = strings.Split(tag, ",")[0]
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+		fields[strings.ToLower(name)] = i
+	}
+	return fields
+}
+
+// parseValue converts the raw string value into the go value of the athena column type.
+// NULL is returned as nil.
+func parseValue(columnType string, raw *string) (interface{}, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	value := *raw
+	switch baseType(columnType) {
+	case "tinyint", "smallint", "integer", "int", "bigint":
+		return strconv.ParseInt(value, 10, 64)
+	case "double", "float", "real":
+		return strconv.ParseFloat(value, 64)
+	case "boolean":
+		return strconv.ParseBool(value)
+	case "date":
+		return parseTime(value, dateLayouts)
+	case "timestamp", "timestamp with time zone":
+		return parseTime(value, timestampLayouts)
+	case "array":
+		elements := []interface{}{}
+		for _, element := range splitNested(strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")) {
+			elements = append(elements, element)
+		}
+		return elements, nil
+	case "map":
+		entries := map[string]interface{}{}
+		for _, entry := range splitNested(strings.TrimSuffix(strings.TrimPrefix(value, "{"), "}")) {
+			kv := strings.SplitN(entry, "=", 2)
+			if len(kv) != 2 {
+				return nil, errors.New(fmt.Sprintf("Invalid map entry entry=%s", entry))
+			}
+			entries[kv[0]] = kv[1]
+		}
+		return entries, nil
+	}
+	// varchar, char, decimal and the other types are kept as string to avoid losing precision
+	return value, nil
+}
+
+// baseType strips parameters like decimal(10,2) or varchar(255) from the column type
+func baseType(columnType string) string {
+	columnType = strings.ToLower(strings.TrimSpace(columnType))
+	if i := strings.Index(columnType, "("); i >= 0 {
+		return columnType[:i]
+	}
+	return columnType
+}
+
+func parseTime(value string, layouts []string) (time.Time, error) {
+	var lastErr error
+	for _, layout := range layouts {
+		t, err := time.Parse(layout, value)
+		if err == nil {
+			return t, nil
+		}
+		lastErr = err
+	}
+	return time.Time{}, lastErr
+}
+
+// splitNested splits the elements of athena array or map text by ", " ignoring nested brackets
+func splitNested(value string) []string {
+	if value == "" {
+		return nil
+	}
+	elements := []string{}
+	depth := 0
+	start := 0
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '[', '{', '(':
+			depth++
+		case ']', '}', ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				elements = append(elements, strings.TrimSpace(value[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(elements, strings.TrimSpace(value[start:]))
+}
+
+// assignValue sets the parsed value into the field. raw is used when the field type differs
+// from the column type, e.g. a varchar column holding numbers.
+func assignValue(field reflect.Value, value interface{}, raw string) error {
+	if value == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+	if field.Kind() == reflect.Ptr {
+		elem := reflect.New(field.Type().Elem())
+		if err := assignValue(elem.Elem(), value, raw); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+	v := reflect.ValueOf(value)
+	if field.Kind() == reflect.Interface || v.Type() == field.Type() {
+		field.Set(v)
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := value.(int64)
+		if !ok {
+			parsed, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				return err
+			}
+			n = parsed
+		}
+		if field.OverflowInt(n) {
+			return errors.New(fmt.Sprintf("Value overflows field value=%d type=%s", n, field.Type()))
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		if field.OverflowUint(n) {
+			return errors.New(fmt.Sprintf("Value overflows field value=%d type=%s", n, field.Type()))
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, ok := value.(float64)
+		if !ok {
+			parsed, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return err
+			}
+			f = parsed
+		}
+		field.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Slice:
+		elements, ok := value.([]interface{})
+		if !ok {
+			return errors.New(fmt.Sprintf("Cannot set value into slice type=%s", field.Type()))
+		}
+		slice := reflect.MakeSlice(field.Type(), len(elements), len(elements))
+		for i, element := range elements {
+			text := fmt.Sprint(element)
+			if err := assignValue(slice.Index(i), text, text); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+	case reflect.Map:
+		entries, ok := value.(map[string]interface{})
+		if !ok || field.Type().Key().Kind() != reflect.String {
+			return errors.New(fmt.Sprintf("Cannot set value into map type=%s", field.Type()))
+		}
+		m := reflect.MakeMapWithSize(field.Type(), len(entries))
+		for k, entry := range entries {
+			text := fmt.Sprint(entry)
+			elem := reflect.New(field.Type().Elem()).Elem()
+			if err := assignValue(elem, text, text); err != nil {
+				return err
+			}
+			m.SetMapIndex(reflect.ValueOf(k).Convert(field.Type().Key()), elem)
+		}
+		field.Set(m)
+	default:
+		return errors.New(fmt.Sprintf("Unsupported field type=%s value=%T", field.Type(), value))
+	}
+	return nil
+}
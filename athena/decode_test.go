@@ -0,0 +1,196 @@
+package athena
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+)
+
+type testResultRecord struct {
+	ID        int64             `athena:"id"`
+	Name      *string           `athena:"name"`
+	Score     float64           `athena:"score"`
+	Active    bool              `athena:"active"`
+	Day       time.Time         `athena:"day"`
+	CreatedAt time.Time         `athena:"created_at"`
+	Tags      []string          `athena:"tags"`
+	Attrs     map[string]string `athena:"attrs"`
+	Price     float64           `athena:"price"`
+	Ignored   string            `athena:"-"`
+}
+
+func makeRow(values ...*string) *athena.Row {
+	row := &athena.Row{}
+	for _, value := range values {
+		row.Data = append(row.Data, &athena.Datum{VarCharValue: value})
+	}
+	return row
+}
+
+func makeColumns(nameTypes ...string) []*athena.ColumnInfo {
+	columns := []*athena.ColumnInfo{}
+	for i := 0; i < len(nameTypes); i += 2 {
+		columns = append(columns, &athena.ColumnInfo{
+			Name:  aws.String(nameTypes[i]),
+			Label: aws.String(nameTypes[i]),
+			Type:  aws.String(nameTypes[i+1]),
+		})
+	}
+	return columns
+}
+
+func makeTypedResultsPage() *athena.GetQueryResultsOutput {
+	columns := makeColumns(
+		"id", "bigint",
+		"name", "varchar",
+		"score", "double",
+		"active", "boolean",
+		"day", "date",
+		"created_at", "timestamp",
+		"tags", "array",
+		"attrs", "map",
+		"price", "decimal",
+	)
+	return &athena.GetQueryResultsOutput{
+		ResultSet: &athena.ResultSet{
+			ResultSetMetadata: &athena.ResultSetMetadata{ColumnInfo: columns},
+			Rows: []*athena.Row{
+				makeRow(aws.String("id"), aws.String("name"), aws.String("score"), aws.String("active"),
+					aws.String("day"), aws.String("created_at"), aws.String("tags"), aws.String("attrs"),
+					aws.String("price")),
+				makeRow(aws.String("1"), aws.String("foo"), aws.String("1.5"), aws.String("true"),
+					aws.String("2019-12-01"), aws.String("2019-12-01 10:20:30.123"), aws.String("[a, b]"),
+					aws.String("{k1=v1, k2=v2}"), aws.String("10.25")),
+				makeRow(aws.String("2"), nil, nil, aws.String("false"),
+					nil, nil, aws.String("[]"), nil, nil),
+			},
+		},
+	}
+}
+
+func TestUnmarshalResultsIntoStruct(t *testing.T) {
+	var records []testResultRecord
+	if err := UnmarshalResults(makeTypedResultsPage(), &records); err != nil {
+		t.Fatalf("Unmarshal results failure %s", err.Error())
+	}
+	if len(records) != 2 {
+		t.Fatalf("Wrong records length:%d", len(records))
+	}
+
+	first := records[0]
+	if first.ID != 1 || aws.StringValue(first.Name) != "foo" || first.Score != 1.5 || !first.Active {
+		t.Fatalf("Wrong first record %+v", first)
+	}
+	if first.Day != time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC) {
+		t.Fatalf("Wrong day %s", first.Day)
+	}
+	if first.CreatedAt != time.Date(2019, 12, 1, 10, 20, 30, 123000000, time.UTC) {
+		t.Fatalf("Wrong created_at %s", first.CreatedAt)
+	}
+	if len(first.Tags) != 2 || first.Tags[1] != "b" {
+		t.Fatalf("Wrong tags %+v", first.Tags)
+	}
+	if first.Attrs["k2"] != "v2" {
+		t.Fatalf("Wrong attrs %+v", first.Attrs)
+	}
+	if first.Price != 10.25 {
+		t.Fatalf("Wrong price %f", first.Price)
+	}
+
+	second := records[1]
+	if second.Name != nil || second.Score != 0 || !second.Day.IsZero() || len(second.Tags) != 0 {
+		t.Fatalf("NULL values must be zero values %+v", second)
+	}
+}
+
+func TestUnmarshalResultsIntoMap(t *testing.T) {
+	var records []map[string]interface{}
+	if err := UnmarshalResults(makeTypedResultsPage(), &records); err != nil {
+		t.Fatalf("Unmarshal results failure %s", err.Error())
+	}
+	if len(records) != 2 {
+		t.Fatalf("Wrong records length:%d", len(records))
+	}
+	if records[0]["id"] != int64(1) {
+		t.Fatalf("Wrong id %#v", records[0]["id"])
+	}
+	if records[0]["price"] != "10.25" {
+		t.Fatalf("Decimal must be kept as string %#v", records[0]["price"])
+	}
+	if records[1]["name"] != nil {
+		t.Fatalf("NULL must be nil %#v", records[1]["name"])
+	}
+}
+
+func TestUnmarshalResultsIntoTypedMap(t *testing.T) {
+	var records []map[string]string
+	if err := UnmarshalResults(makeTypedResultsPage(), &records); err != nil {
+		t.Fatalf("Unmarshal results failure %s", err.Error())
+	}
+	if len(records) != 2 || records[0]["id"] != "1" || records[0]["score"] != "1.5" || records[0]["tags"] != "[a, b]" {
+		t.Fatalf("Values must be the raw text %v", records)
+	}
+	if value, ok := records[1]["name"]; !ok || value != "" {
+		t.Fatalf("NULL must be the zero value %#v", value)
+	}
+
+	page := &athena.GetQueryResultsOutput{
+		ResultSet: &athena.ResultSet{
+			ResultSetMetadata: &athena.ResultSetMetadata{ColumnInfo: makeColumns("id", "bigint", "count", "bigint")},
+			Rows:              []*athena.Row{makeRow(aws.String("1"), aws.String("10"))},
+		},
+	}
+	var counts []map[string]int
+	if err := UnmarshalResults(page, &counts); err != nil {
+		t.Fatalf("Unmarshal results failure %s", err.Error())
+	}
+	if counts[0]["id"] != 1 || counts[0]["count"] != 10 {
+		t.Fatalf("Values must be converted into int %v", counts)
+	}
+
+	var channels []map[string]chan int
+	if err := UnmarshalResults(page, &channels); err == nil {
+		t.Fatalf("Unsupported map value type must be error")
+	}
+}
+
+func TestResultDecoderDropsHeaderOnlyOnFirstPage(t *testing.T) {
+	columns := makeColumns("name", "varchar")
+	decoder := NewResultDecoder()
+	var records []*struct{ Name string }
+
+	pages := []*athena.GetQueryResultsOutput{
+		{
+			ResultSet: &athena.ResultSet{
+				ResultSetMetadata: &athena.ResultSetMetadata{ColumnInfo: columns},
+				Rows:              []*athena.Row{makeRow(aws.String("name")), makeRow(aws.String("first"))},
+			},
+		},
+		{
+			ResultSet: &athena.ResultSet{
+				ResultSetMetadata: &athena.ResultSetMetadata{ColumnInfo: columns},
+				Rows:              []*athena.Row{makeRow(aws.String("name")), makeRow(aws.String("last"))},
+			},
+		},
+	}
+	for _, page := range pages {
+		if err := decoder.Decode(page, &records); err != nil {
+			t.Fatalf("Decode failure %s", err.Error())
+		}
+	}
+	if len(records) != 3 {
+		t.Fatalf("Wrong records length:%d", len(records))
+	}
+	if records[0].Name != "first" || records[1].Name != "name" || records[2].Name != "last" {
+		t.Fatalf("Wrong records %+v %+v %+v", records[0], records[1], records[2])
+	}
+}
+
+func TestUnmarshalResultsInvalidDestination(t *testing.T) {
+	var records []testResultRecord
+	if err := UnmarshalResults(makeTypedResultsPage(), records); err == nil {
+		t.Fatalf("Non pointer destination must be rejected")
+	}
+}
@@ -0,0 +1,131 @@
+package athena
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/athena"
+	"github.com/pkg/errors"
+)
+
+// Error categories of QueryFailedError reported by athena
+const (
+	ErrorCategorySystem int64 = 1
+	ErrorCategoryUser   int64 = 2
+	ErrorCategoryOther  int64 = 3
+)
+
+// throttlingErrorCode is the code of throttling errors other than TooManyRequestsException
+const throttlingErrorCode = "ThrottlingException"
+
+// QueryFailedError is returned when the query execution ends with FAILED state
+type QueryFailedError struct {
+	JobID string
+	// StateChangeReason is the reason of the failure reported by athena
+	StateChangeReason string
+	// ErrorCategory is one of ErrorCategorySystem, ErrorCategoryUser and ErrorCategoryOther.
+	// Zero means athena did not report the category.
+	ErrorCategory int64
+	// ErrorType is the detailed error type listed in the error type reference of athena
+	ErrorType int64
+	// ErrorMessage is the short description of the error
+	ErrorMessage string
+	// Retryable reports whether the query might succeed if resubmitted
+	Retryable bool
+}
+
+func newQueryFailedError(jobID string, status *athena.QueryExecutionStatus) *QueryFailedError {
+	e := &QueryFailedError{
+		JobID:             jobID,
+		StateChangeReason: aws.StringValue(status.StateChangeReason),
+	}
+	if status.AthenaError != nil {
+		e.ErrorCategory = aws.Int64Value(status.AthenaError.ErrorCategory)
+		e.ErrorType = aws.Int64Value(status.AthenaError.ErrorType)
+		e.ErrorMessage = aws.StringValue(status.AthenaError.ErrorMessage)
+		e.Retryable = aws.BoolValue(status.AthenaError.Retryable)
+	}
+	return e
+}
+
+func (e *QueryFailedError) Error() string {
+	message := fmt.Sprintf("Job is failed jobId:%s", e.JobID)
+	if e.StateChangeReason != "" {
+		message += fmt.Sprintf(" reason:%s", e.StateChangeReason)
+	}
+	if e.ErrorCategory != 0 {
+		message += fmt.Sprintf(" category:%d type:%d", e.ErrorCategory, e.ErrorType)
+	}
+	return message
+}
+
+// IsUserError reports whether the query failed because of the query itself, e.g. a syntax error
+// or a missing table, so resubmitting the same query does not help
+func (e *QueryFailedError) IsUserError() bool {
+	return e.ErrorCategory == ErrorCategoryUser
+}
+
+// IsSystemError reports whether the query failed because of athena itself
+func (e *QueryFailedError) IsSystemError() bool {
+	return e.ErrorCategory == ErrorCategorySystem
+}
+
+// QueryCancelledError is returned when the query execution ends with CANCELLED state
+type QueryCancelledError struct {
+	JobID string
+	// StateChangeReason is the reason of the cancellation reported by athena
+	StateChangeReason string
+}
+
+func (e *QueryCancelledError) Error() string {
+	if e.StateChangeReason == "" {
+		return fmt.Sprintf("Job is canceled jobId:%s", e.JobID)
+	}
+	return fmt.Sprintf("Job is canceled jobId:%s reason:%s", e.JobID, e.StateChangeReason)
+}
+
+// ThrottledError is returned when athena rejects a request because of too many requests.
+// The original aws error is available by errors.As or errors.Unwrap.
+type ThrottledError struct {
+	// Operation is the name of the rejected athena API
+	Operation string
+	Err       awserr.Error
+}
+
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("Request is throttled operation:%s (%s)", e.Operation, e.Err.Error())
+}
+
+func (e *ThrottledError) Unwrap() error {
+	return e.Err
+}
+
+// UnknownStateError is returned when the query execution is in a state the wrapper does not know
+type UnknownStateError struct {
+	JobID string
+	State string
+}
+
+func (e *UnknownStateError) Error() string {
+	return fmt.Sprintf("Invalid state code jobId:%s state:%s", e.JobID, e.State)
+}
+
+// throttled returns ThrottledError if err is a throttling error of athena, and err as is otherwise
+func throttled(operation string, err error) error {
+	awsErr, ok := err.(awserr.Error)
+	if !ok {
+		return err
+	}
+	switch awsErr.Code() {
+	case athena.ErrCodeTooManyRequestsException, throttlingErrorCode:
+		return &ThrottledError{Operation: operation, Err: awsErr}
+	}
+	return err
+}
+
+// isThrottled reports whether athena rejected the request because of too many requests
+func isThrottled(err error) bool {
+	var throttledErr *ThrottledError
+	return errors.As(err, &throttledErr)
+}
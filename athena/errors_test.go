@@ -0,0 +1,93 @@
+package athena
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/athena"
+)
+
+func TestQueryFailedErrorFromAthenaError(t *testing.T) {
+	outputCh := makeStateCh("FAILED")
+	status := (<-outputCh).QueryExecution.Status
+	status.StateChangeReason = aws.String("SYNTAX_ERROR: line 1:8")
+	status.AthenaError = &athena.AthenaError{
+		ErrorCategory: aws.Int64(ErrorCategoryUser),
+		ErrorType:     aws.Int64(1006),
+		ErrorMessage:  aws.String("line 1:8: mismatched input"),
+		Retryable:     aws.Bool(false),
+	}
+	outputCh <- &athena.GetQueryExecutionOutput{
+		QueryExecution: &athena.QueryExecution{QueryExecutionId: aws.String("dummyQueryID"), Status: status},
+	}
+	athenaMock := &AthenaMock{GetQueryExecutionOutputCh: outputCh}
+	athenaWrapper := New(athenaMock, "test-db")
+
+	_, err := athenaWrapper.RunQuery(context.Background(), "dummyQuery", &RunOptions{
+		Wait: &WaitOptions{InitialInterval: time.Millisecond},
+	})
+	var failedErr *QueryFailedError
+	if !errors.As(err, &failedErr) {
+		t.Fatalf("Wrapped error must be QueryFailedError err=%v", err)
+	}
+	if failedErr.StateChangeReason != "SYNTAX_ERROR: line 1:8" || failedErr.ErrorType != 1006 ||
+		failedErr.ErrorMessage != "line 1:8: mismatched input" || failedErr.Retryable {
+		t.Fatalf("Invalid failed error %+v", failedErr)
+	}
+	if !failedErr.IsUserError() || failedErr.IsSystemError() {
+		t.Fatalf("Failed error must be user error category=%d", failedErr.ErrorCategory)
+	}
+}
+
+func TestUnknownStateError(t *testing.T) {
+	athenaMock := &AthenaMock{GetQueryExecutionOutputCh: makeStateCh("UNKNOWN")}
+	athenaWrapper := New(athenaMock, "test-db")
+
+	_, err := athenaWrapper.WaitJobStatusContext(context.Background(), "dummyID", nil)
+	var stateErr *UnknownStateError
+	if !errors.As(err, &stateErr) {
+		t.Fatalf("Error must be UnknownStateError err=%v", err)
+	}
+	if stateErr.JobID != "dummyID" || stateErr.State != "UNKNOWN" {
+		t.Fatalf("Invalid unknown state error %+v", stateErr)
+	}
+}
+
+func TestThrottledError(t *testing.T) {
+	athenaMock := &batchAthenaMock{AthenaMock: &AthenaMock{}, throttles: 1}
+	athenaWrapper := New(athenaMock, "test-db")
+
+	_, err := athenaWrapper.ExecuteQuery("dummyQuery", "s3://test-bucket")
+	var throttledErr *ThrottledError
+	if !errors.As(err, &throttledErr) {
+		t.Fatalf("Error must be ThrottledError err=%v", err)
+	}
+	if throttledErr.Operation != "StartQueryExecution" {
+		t.Fatalf("Invalid operation %s", throttledErr.Operation)
+	}
+	var awsErr awserr.Error
+	if !errors.As(err, &awsErr) || awsErr.Code() != athena.ErrCodeTooManyRequestsException {
+		t.Fatalf("Throttled error must unwrap to the aws error err=%v", err)
+	}
+
+	if err := throttled("StartQueryExecution", awserr.New(athena.ErrCodeInvalidRequestException, "dummy", nil)); isThrottled(err) {
+		t.Fatalf("Invalid request must not be throttled error")
+	}
+}
+
+func TestWaitTimeoutError(t *testing.T) {
+	athenaMock := &AthenaMock{GetQueryExecutionOutputCh: makeStateCh("RUNNING", "RUNNING", "RUNNING")}
+	athenaWrapper := New(athenaMock, "test-db")
+
+	_, err := athenaWrapper.WaitJobStatusContext(context.Background(), "dummyID", &WaitOptions{
+		InitialInterval: time.Second,
+		MaxWait:         10 * time.Millisecond,
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Timed out wait must be deadline exceeded err=%v", err)
+	}
+}
@@ -0,0 +1,148 @@
+package athena
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+	"github.com/pkg/errors"
+)
+
+var tsvReplacer = strings.NewReplacer("\\", "\\\\", "\t", "\\t", "\n", "\\n", "\r", "\\r")
+
+// ExportCSV writes the rows of the iterator to w as CSV with the header of column names.
+// NULL is written as empty field. It returns the number of written rows.
+// Rows are written page by page, so the whole results are never kept in memory.
+func ExportCSV(w io.Writer, it *RowIterator) (int64, error) {
+	writer := csv.NewWriter(w)
+	count, err := exportRows(it, func(columns []*athena.ColumnInfo) error {
+		return writer.Write(columnNames(columns))
+	}, func(row *athena.Row) error {
+		return writer.Write(rowValues(row, func(s string) string { return s }))
+	})
+	writer.Flush()
+	if err == nil {
+		err = writer.Error()
+	}
+	return count, err
+}
+
+// ExportTSV writes the rows of the iterator to w as TSV with the header of column names.
+// Backslash, tab and newlines in values are escaped like \t, and NULL is written as empty field.
+// It returns the number of written rows.
+func ExportTSV(w io.Writer, it *RowIterator) (int64, error) {
+	writer := bufio.NewWriter(w)
+	writeLine := func(values []string) error {
+		_, err := writer.WriteString(strings.Join(values, "\t") + "\n")
+		return err
+	}
+	count, err := exportRows(it, func(columns []*athena.ColumnInfo) error {
+		names := columnNames(columns)
+		for i, name := range names {
+			names[i] = tsvReplacer.Replace(name)
+		}
+		return writeLine(names)
+	}, func(row *athena.Row) error {
+		return writeLine(rowValues(row, tsvReplacer.Replace))
+	})
+	if flushErr := writer.Flush(); err == nil {
+		err = flushErr
+	}
+	return count, err
+}
+
+// ExportJSONLines writes the rows of the iterator to w as JSON Lines.
+// Every line is an object keyed by column names in the column order, and the values are typed
+// by the column metadata like ResultDecoder does. NaN and infinite doubles, which JSON cannot
+// represent as numbers, are written as the strings "NaN", "Infinity" and "-Infinity" as Athena does.
+// It returns the number of written rows.
+func ExportJSONLines(w io.Writer, it *RowIterator) (int64, error) {
+	writer := bufio.NewWriter(w)
+	count, err := exportRows(it, nil, func(row *athena.Row) error {
+		record, err := decodeRow(it.Columns(), row)
+		if err != nil {
+			return err
+		}
+		writer.WriteByte('{')
+		for i, column := range it.Columns() {
+			if i > 0 {
+				writer.WriteByte(',')
+			}
+			name := aws.StringValue(column.Name)
+			key, _ := json.Marshal(name)
+			value, err := json.Marshal(jsonValue(record[name]))
+			if err != nil {
+				return errors.Wrap(err, "Json marshal failure column="+name)
+			}
+			writer.Write(key)
+			writer.WriteByte(':')
+			writer.Write(value)
+		}
+		_, err = writer.WriteString("}\n")
+		return err
+	})
+	if flushErr := writer.Flush(); err == nil {
+		err = flushErr
+	}
+	return count, err
+}
+
+// jsonValue replaces the non-finite float of the value by its string
+func jsonValue(value interface{}) interface{} {
+	f, ok := value.(float64)
+	switch {
+	case !ok:
+		return value
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	}
+	return value
+}
+
+// exportRows calls writeHeader once the columns are known and writeRow for every row
+func exportRows(
+	it *RowIterator, writeHeader func([]*athena.ColumnInfo) error, writeRow func(*athena.Row) error) (int64, error) {
+
+	var count int64
+	hasRow := it.Next()
+	if writeHeader != nil && len(it.Columns()) > 0 {
+		if err := writeHeader(it.Columns()); err != nil {
+			return count, errors.Wrap(err, "Write header failure")
+		}
+	}
+	for ; hasRow; hasRow = it.Next() {
+		if err := writeRow(it.Row()); err != nil {
+			return count, errors.Wrap(err, "Write row failure")
+		}
+		count++
+	}
+	if err := it.Err(); err != nil {
+		return count, errors.Wrap(err, "Read rows failure")
+	}
+	return count, nil
+}
+
+func columnNames(columns []*athena.ColumnInfo) []string {
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = aws.StringValue(column.Name)
+	}
+	return names
+}
+
+func rowValues(row *athena.Row, escape func(string) string) []string {
+	values := make([]string, len(row.Data))
+	for i, datum := range row.Data {
+		values[i] = escape(aws.StringValue(datum.VarCharValue))
+	}
+	return values
+}
@@ -0,0 +1,87 @@
+package athena
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+)
+
+func makeExportPages() []*athena.GetQueryResultsOutput {
+	columns := makeColumns("id", "bigint", "name", "varchar")
+	pages := makeResultPages(columns, []string{}, []string{})
+	pages[0].ResultSet.Rows = append(pages[0].ResultSet.Rows,
+		makeRow(aws.String("1"), aws.String("foo,\"bar\"")))
+	pages[1].ResultSet.Rows = append(pages[1].ResultSet.Rows,
+		makeRow(aws.String("2"), aws.String("tab\there")),
+		makeRow(aws.String("3"), nil))
+	return pages
+}
+
+func TestExportCSV(t *testing.T) {
+	athenaWrapper := New(&AthenaMock{ResultPages: makeExportPages()}, "test-db")
+	var buf bytes.Buffer
+	count, err := ExportCSV(&buf, athenaWrapper.Rows(context.Background(), "dummyJob", 0))
+	if err != nil {
+		t.Fatalf("Export csv failure %s", err.Error())
+	}
+	expected := "id,name\n1,\"foo,\"\"bar\"\"\"\n2,tab\there\n3,\n"
+	if count != 3 || buf.String() != expected {
+		t.Fatalf("Wrong csv count=%d\n%s", count, buf.String())
+	}
+}
+
+func TestExportTSV(t *testing.T) {
+	athenaWrapper := New(&AthenaMock{ResultPages: makeExportPages()}, "test-db")
+	var buf bytes.Buffer
+	count, err := ExportTSV(&buf, athenaWrapper.Rows(context.Background(), "dummyJob", 0))
+	if err != nil {
+		t.Fatalf("Export tsv failure %s", err.Error())
+	}
+	expected := "id\tname\n1\tfoo,\"bar\"\n2\ttab\\there\n3\t\n"
+	if count != 3 || buf.String() != expected {
+		t.Fatalf("Wrong tsv count=%d\n%s", count, buf.String())
+	}
+}
+
+func TestExportJSONLines(t *testing.T) {
+	athenaWrapper := New(&AthenaMock{ResultPages: makeExportPages()}, "test-db")
+	var buf bytes.Buffer
+	count, err := ExportJSONLines(&buf, athenaWrapper.Rows(context.Background(), "dummyJob", 0))
+	if err != nil {
+		t.Fatalf("Export json lines failure %s", err.Error())
+	}
+	expected := "{\"id\":1,\"name\":\"foo,\\\"bar\\\"\"}\n{\"id\":2,\"name\":\"tab\\there\"}\n{\"id\":3,\"name\":null}\n"
+	if count != 3 || buf.String() != expected {
+		t.Fatalf("Wrong json lines count=%d\n%s", count, buf.String())
+	}
+}
+
+func TestExportCSVEmpty(t *testing.T) {
+	pages := makeResultPages(makeColumns("id", "bigint"), []string{})
+	athenaWrapper := New(&AthenaMock{ResultPages: pages}, "test-db")
+	var buf bytes.Buffer
+	count, err := ExportCSV(&buf, athenaWrapper.Rows(context.Background(), "dummyJob", 0))
+	if err != nil {
+		t.Fatalf("Export csv failure %s", err.Error())
+	}
+	if count != 0 || buf.String() != "id\n" {
+		t.Fatalf("Empty results must have only header count=%d\n%s", count, buf.String())
+	}
+}
+
+func TestExportJSONLinesNonFiniteDouble(t *testing.T) {
+	pages := makeResultPages(makeColumns("score", "double"), []string{"1.5", "NaN", "Infinity", "-Infinity"})
+	athenaWrapper := New(&AthenaMock{ResultPages: pages}, "test-db")
+	var buf bytes.Buffer
+	count, err := ExportJSONLines(&buf, athenaWrapper.Rows(context.Background(), "dummyJob", 0))
+	if err != nil {
+		t.Fatalf("Export json lines failure %s", err.Error())
+	}
+	expected := "{\"score\":1.5}\n{\"score\":\"NaN\"}\n{\"score\":\"Infinity\"}\n{\"score\":\"-Infinity\"}\n"
+	if count != 4 || buf.String() != expected {
+		t.Fatalf("Wrong json lines count=%d\n%s", count, buf.String())
+	}
+}
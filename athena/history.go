@@ -0,0 +1,153 @@
+package athena
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+	"github.com/pkg/errors"
+)
+
+// maxBatchGetQueryExecution is the number of ids BatchGetQueryExecution accepts at once
+const maxBatchGetQueryExecution = 50
+
+// ExecutionFilter selects query executions of ListExecutions
+type ExecutionFilter struct {
+	// WorkGroup is the workgroup of the executions. Default is the workgroup of the client options.
+	WorkGroup string
+	// States selects the executions in the states. Empty selects all states.
+	States []string
+	// Since and Until select the executions submitted in the time window. Zero means no limit.
+	Since time.Time
+	Until time.Time
+	// QueryContains selects the executions whose query contains the text case insensitively
+	QueryContains string
+	// Limit is the maximum number of executions. Zero means no limit.
+	Limit int
+}
+
+func (f *ExecutionFilter) match(summary *ExecutionSummary) bool {
+	if len(f.States) > 0 {
+		matched := false
+		for _, state := range f.States {
+			if strings.EqualFold(state, summary.State) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if !f.Since.IsZero() && summary.SubmittedAt.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && summary.SubmittedAt.After(f.Until) {
+		return false
+	}
+	if f.QueryContains != "" && !strings.Contains(strings.ToLower(summary.Query), strings.ToLower(f.QueryContains)) {
+		return false
+	}
+	return true
+}
+
+// ExecutionSummary is the summary of a past query execution
+type ExecutionSummary struct {
+	JobID             string
+	Query             string
+	State             string
+	StateChangeReason string
+	StatementType     string
+	Database          string
+	WorkGroup         string
+	OutputLocation    string
+	SubmittedAt       time.Time
+	CompletedAt       time.Time
+	Stats             *QueryStats
+}
+
+func (s *wrapperAthena) newExecutionSummary(execution *athena.QueryExecution) *ExecutionSummary {
+	jobID := aws.StringValue(execution.QueryExecutionId)
+	summary := &ExecutionSummary{
+		JobID:         jobID,
+		Query:         aws.StringValue(execution.Query),
+		StatementType: aws.StringValue(execution.StatementType),
+		WorkGroup:     aws.StringValue(execution.WorkGroup),
+		Stats:         newQueryStats(jobID, execution, s.executionConfig(nil).pricePerTB),
+	}
+	if status := execution.Status; status != nil {
+		summary.State = aws.StringValue(status.State)
+		summary.StateChangeReason = aws.StringValue(status.StateChangeReason)
+		summary.SubmittedAt = aws.TimeValue(status.SubmissionDateTime)
+		summary.CompletedAt = aws.TimeValue(status.CompletionDateTime)
+	}
+	if execution.QueryExecutionContext != nil {
+		summary.Database = aws.StringValue(execution.QueryExecutionContext.Database)
+	}
+	if execution.ResultConfiguration != nil {
+		summary.OutputLocation = aws.StringValue(execution.ResultConfiguration.OutputLocation)
+	}
+	return summary
+}
+
+// ListExecutions returns the past executions selected by the filter, newest first as athena lists them.
+// Since athena lists newer executions first, the listing stops at the first page whose executions
+// are all submitted before filter.Since.
+func (s *wrapperAthena) ListExecutions(ctx context.Context, filter *ExecutionFilter) ([]*ExecutionSummary, error) {
+	if filter == nil {
+		filter = &ExecutionFilter{}
+	}
+	workGroup := filter.WorkGroup
+	if workGroup == "" {
+		workGroup = s.executionConfig(nil).workGroup
+	}
+	input := &athena.ListQueryExecutionsInput{
+		MaxResults: aws.Int64(maxBatchGetQueryExecution),
+	}
+	if workGroup != "" {
+		input.WorkGroup = aws.String(workGroup)
+	}
+
+	summaries := []*ExecutionSummary{}
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		page, err := s.Client.ListQueryExecutions(input)
+		if err != nil {
+			return nil, errors.Wrap(err, "List query executions failure")
+		}
+		allOlder := len(page.QueryExecutionIds) > 0
+		for start := 0; start < len(page.QueryExecutionIds); start += maxBatchGetQueryExecution {
+			end := start + maxBatchGetQueryExecution
+			if end > len(page.QueryExecutionIds) {
+				end = len(page.QueryExecutionIds)
+			}
+			output, err := s.Client.BatchGetQueryExecution(&athena.BatchGetQueryExecutionInput{
+				QueryExecutionIds: page.QueryExecutionIds[start:end],
+			})
+			if err != nil {
+				return nil, errors.Wrap(err, "Batch get query executions failure")
+			}
+			for _, execution := range output.QueryExecutions {
+				summary := s.newExecutionSummary(execution)
+				if filter.Since.IsZero() || !summary.SubmittedAt.Before(filter.Since) {
+					allOlder = false
+				}
+				if !filter.match(summary) {
+					continue
+				}
+				summaries = append(summaries, summary)
+				if filter.Limit > 0 && len(summaries) >= filter.Limit {
+					return summaries, nil
+				}
+			}
+		}
+		if allOlder || aws.StringValue(page.NextToken) == "" {
+			return summaries, nil
+		}
+		input.NextToken = page.NextToken
+	}
+}
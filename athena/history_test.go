@@ -0,0 +1,96 @@
+package athena
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+)
+
+// makeExecutions returns executions submitted every hour backward from base, newest first
+func makeExecutions(n int, base time.Time) []*athena.QueryExecution {
+	executions := []*athena.QueryExecution{}
+	for i := 0; i < n; i++ {
+		state := "SUCCEEDED"
+		reason := ""
+		if i%3 == 0 {
+			state = "FAILED"
+			reason = fmt.Sprintf("dummy failure %d", i)
+		}
+		executions = append(executions, &athena.QueryExecution{
+			QueryExecutionId: aws.String(fmt.Sprintf("dummyQueryID%d", i)),
+			Query:            aws.String(fmt.Sprintf("SELECT * FROM logs_%d", i%2)),
+			StatementType:    aws.String("DML"),
+			Status: &athena.QueryExecutionStatus{
+				State:              aws.String(state),
+				StateChangeReason:  aws.String(reason),
+				SubmissionDateTime: aws.Time(base.Add(-time.Duration(i) * time.Hour)),
+			},
+			Statistics: &athena.QueryExecutionStatistics{
+				DataScannedInBytes: aws.Int64(int64(i) << 20),
+			},
+		})
+	}
+	return executions
+}
+
+func TestListExecutions(t *testing.T) {
+	base := time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC)
+	athenaMock := &AthenaMock{Executions: makeExecutions(120, base)}
+	athenaWrapper := New(athenaMock, "test-db")
+
+	summaries, err := athenaWrapper.ListExecutions(context.Background(), &ExecutionFilter{
+		States:        []string{"failed"},
+		QueryContains: "LOGS_1",
+	})
+	if err != nil {
+		t.Fatalf("List executions failure %s", err.Error())
+	}
+	// Failed are 0, 3, 6, ..., and logs_1 are odd, so 3, 9, 15, ..., 117
+	if len(summaries) != 20 {
+		t.Fatalf("Wrong summaries length %d", len(summaries))
+	}
+	first := summaries[0]
+	if first.JobID != "dummyQueryID3" || first.StateChangeReason != "dummy failure 3" || first.Stats.DataScannedBytes != 3<<20 {
+		t.Fatalf("Wrong summary %+v", first)
+	}
+	if athenaMock.ListQueryExecutionsCounter != 3 {
+		t.Fatalf("Wrong list counter %d", athenaMock.ListQueryExecutionsCounter)
+	}
+}
+
+func TestListExecutionsTimeWindow(t *testing.T) {
+	base := time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC)
+	athenaMock := &AthenaMock{Executions: makeExecutions(200, base)}
+	athenaWrapper := New(athenaMock, "test-db")
+
+	summaries, err := athenaWrapper.ListExecutions(context.Background(), &ExecutionFilter{
+		Since: base.Add(-60 * time.Hour),
+		Until: base.Add(-10 * time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("List executions failure %s", err.Error())
+	}
+	if len(summaries) != 51 {
+		t.Fatalf("Wrong summaries length %d", len(summaries))
+	}
+	if athenaMock.ListQueryExecutionsCounter != 3 {
+		t.Fatalf("Listing must stop at the page older than since counter=%d", athenaMock.ListQueryExecutionsCounter)
+	}
+}
+
+func TestListExecutionsLimit(t *testing.T) {
+	athenaMock := &AthenaMock{Executions: makeExecutions(120, time.Now())}
+	athenaWrapper := New(athenaMock, "test-db")
+
+	summaries, err := athenaWrapper.ListExecutions(context.Background(), &ExecutionFilter{Limit: 5})
+	if err != nil {
+		t.Fatalf("List executions failure %s", err.Error())
+	}
+	if len(summaries) != 5 || athenaMock.ListQueryExecutionsCounter != 1 {
+		t.Fatalf("Wrong summaries length %d counter=%d", len(summaries), athenaMock.ListQueryExecutionsCounter)
+	}
+}
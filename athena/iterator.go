@@ -0,0 +1,160 @@
+package athena
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+	"github.com/pkg/errors"
+)
+
+const maxResultsPerPage = 1000
+
+// pageSource fetches the pages of query results one by one
+type pageSource interface {
+	nextPage(ctx context.Context) (page *athena.GetQueryResultsOutput, last bool, err error)
+	close() error
+}
+
+// RowIterator iterates over the rows of query results.
+// Pages are fetched lazily when the rows of the previous page are consumed.
+//
+//	it := wrapper.Rows(ctx, jobID, 1000)
+//	defer it.Close()
+//	for it.Next() {
+//		var record Record
+//		if err := it.Scan(&record); err != nil { ... }
+//	}
+//	if err := it.Err(); err != nil { ... }
+type RowIterator struct {
+	ctx     context.Context
+	source  pageSource
+	decoder *ResultDecoder
+	rows    []*athena.Row
+	current *athena.Row
+	last    bool
+	closed  bool
+	err     error
+	count   int64
+}
+
+func newRowIterator(ctx context.Context, source pageSource) *RowIterator {
+	return &RowIterator{
+		ctx:     ctx,
+		source:  source,
+		decoder: NewResultDecoder(),
+	}
+}
+
+// Next advances the iterator to the next row. It returns false when the rows are exhausted,
+// the iterator is closed or an error occurred.
+func (it *RowIterator) Next() bool {
+	it.current = nil
+	if it.closed || it.err != nil {
+		return false
+	}
+	for len(it.rows) == 0 {
+		if it.last {
+			return false
+		}
+		if err := it.ctx.Err(); err != nil {
+			it.err = err
+			return false
+		}
+		page, last, err := it.source.nextPage(it.ctx)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.last = last
+		it.rows = it.decoder.rows(page)
+	}
+	it.current = it.rows[0]
+	it.rows = it.rows[1:]
+	it.count++
+	return true
+}
+
+// Row returns the current raw row
+func (it *RowIterator) Row() *athena.Row {
+	return it.current
+}
+
+// Columns returns the column metadata of the results. It is available after the first call of Next.
+func (it *RowIterator) Columns() []*athena.ColumnInfo {
+	return it.decoder.columns
+}
+
+// Scan decodes the current row into dest, which must be a pointer to a struct or
+// a pointer to map[string]interface{}. See ResultDecoder.Decode about the mapping.
+func (it *RowIterator) Scan(dest interface{}) error {
+	if it.current == nil {
+		return errors.New("Scan called without current row")
+	}
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return errors.New(fmt.Sprintf("Scan destination must be a pointer dest=%T", dest))
+	}
+	return it.decoder.decodeInto(it.current, v.Elem())
+}
+
+// Count returns the number of rows yielded so far
+func (it *RowIterator) Count() int64 {
+	return it.count
+}
+
+// Err returns the error which stopped the iteration
+func (it *RowIterator) Err() error {
+	return it.err
+}
+
+// Close stops the iteration and releases the buffered rows
+func (it *RowIterator) Close() error {
+	if it.closed {
+		return nil
+	}
+	it.closed = true
+	it.rows = nil
+	it.current = nil
+	return it.source.close()
+}
+
+// queryPageSource fetches pages by GetQueryResults
+type queryPageSource struct {
+	client     AWSAthena
+	jobID      string
+	maxResults int
+	nextToken  *string
+}
+
+func (p *queryPageSource) nextPage(ctx context.Context) (*athena.GetQueryResultsOutput, bool, error) {
+	input := &athena.GetQueryResultsInput{
+		QueryExecutionId: aws.String(p.jobID),
+		MaxResults:       aws.Int64(int64(p.maxResults)),
+		NextToken:        p.nextToken,
+	}
+	page, err := p.client.GetQueryResults(input)
+	if err != nil {
+		return nil, false, errors.Wrap(
+			throttled("GetQueryResults", err), fmt.Sprintf("Get query results failure jobId:%s", p.jobID))
+	}
+	p.nextToken = page.NextToken
+	return page, aws.StringValue(page.NextToken) == "", nil
+}
+
+func (p *queryPageSource) close() error {
+	return nil
+}
+
+func (s *wrapperAthena) Rows(ctx context.Context, jobID string, maxResults int) *RowIterator {
+	if maxResults <= 0 || maxResults > maxResultsPerPage {
+		maxResults = maxResultsPerPage
+	}
+	return newRowIterator(ctx, &queryPageSource{
+		client:     s.Client,
+		jobID:      jobID,
+		maxResults: maxResults,
+	})
+}
@@ -0,0 +1,134 @@
+package athena
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+)
+
+// makeResultPages builds pages whose NextToken is the index of the next page.
+// The header row is put on the first page like athena does.
+func makeResultPages(columns []*athena.ColumnInfo, pages ...[]string) []*athena.GetQueryResultsOutput {
+	outputs := []*athena.GetQueryResultsOutput{}
+	for i, values := range pages {
+		rows := []*athena.Row{}
+		if i == 0 {
+			header := &athena.Row{}
+			for _, column := range columns {
+				header.Data = append(header.Data, &athena.Datum{VarCharValue: column.Name})
+			}
+			rows = append(rows, header)
+		}
+		for _, value := range values {
+			rows = append(rows, makeRow(aws.String(value)))
+		}
+		output := &athena.GetQueryResultsOutput{
+			ResultSet: &athena.ResultSet{
+				ResultSetMetadata: &athena.ResultSetMetadata{ColumnInfo: columns},
+				Rows:              rows,
+			},
+		}
+		if i < len(pages)-1 {
+			output.NextToken = aws.String(strconv.Itoa(i + 1))
+		}
+		outputs = append(outputs, output)
+	}
+	return outputs
+}
+
+func TestRows(t *testing.T) {
+	athenaMock := &AthenaMock{
+		ResultPages: makeResultPages(makeColumns("id", "bigint"), []string{"1", "2"}, []string{}, []string{"3"}),
+	}
+	athenaWrapper := New(athenaMock, "test-db")
+
+	it := athenaWrapper.Rows(context.Background(), "dummyJob", 2)
+	defer it.Close()
+	ids := []int64{}
+	for it.Next() {
+		var record struct {
+			ID int64 `athena:"id"`
+		}
+		if err := it.Scan(&record); err != nil {
+			t.Fatalf("Scan failure %s", err.Error())
+		}
+		ids = append(ids, record.ID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Iterate rows failure %s", err.Error())
+	}
+	if len(ids) != 3 || ids[0] != 1 || ids[2] != 3 {
+		t.Fatalf("Wrong ids %+v", ids)
+	}
+	if it.Count() != 3 {
+		t.Fatalf("Wrong count %d", it.Count())
+	}
+}
+
+func TestRowsScanMap(t *testing.T) {
+	athenaMock := &AthenaMock{
+		ResultPages: makeResultPages(makeColumns("name", "varchar"), []string{"foo"}),
+	}
+	athenaWrapper := New(athenaMock, "test-db")
+
+	it := athenaWrapper.Rows(context.Background(), "dummyJob", 0)
+	defer it.Close()
+	if !it.Next() {
+		t.Fatalf("Iterator must have a row err=%v", it.Err())
+	}
+	record := map[string]interface{}{}
+	if err := it.Scan(&record); err != nil {
+		t.Fatalf("Scan failure %s", err.Error())
+	}
+	if record["name"] != "foo" {
+		t.Fatalf("Wrong record %+v", record)
+	}
+	if aws.StringValue(it.Row().Data[0].VarCharValue) != "foo" {
+		t.Fatalf("Wrong raw row %+v", it.Row())
+	}
+	if it.Next() {
+		t.Fatalf("Iterator must be exhausted")
+	}
+}
+
+func TestRowsCancel(t *testing.T) {
+	athenaMock := &AthenaMock{
+		ResultPages: makeResultPages(makeColumns("id", "bigint"), []string{"1"}, []string{"2"}),
+	}
+	athenaWrapper := New(athenaMock, "test-db")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	it := athenaWrapper.Rows(ctx, "dummyJob", 1)
+	defer it.Close()
+	if !it.Next() {
+		t.Fatalf("Iterator must have a row err=%v", it.Err())
+	}
+	cancel()
+	if it.Next() {
+		t.Fatalf("Cancelled iterator must not fetch next page")
+	}
+	if it.Err() != context.Canceled {
+		t.Fatalf("Wrong error %v", it.Err())
+	}
+}
+
+func TestRowsClose(t *testing.T) {
+	athenaMock := &AthenaMock{
+		ResultPages: makeResultPages(makeColumns("id", "bigint"), []string{"1", "2"}),
+	}
+	athenaWrapper := New(athenaMock, "test-db")
+
+	it := athenaWrapper.Rows(context.Background(), "dummyJob", 0)
+	if !it.Next() {
+		t.Fatalf("Iterator must have a row err=%v", it.Err())
+	}
+	if err := it.Close(); err != nil {
+		t.Fatalf("Close failure %s", err.Error())
+	}
+	if it.Next() {
+		t.Fatalf("Closed iterator must not yield rows")
+	}
+}
@@ -0,0 +1,215 @@
+package athena
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+	"github.com/pkg/errors"
+)
+
+const defaultWorkGroup = "primary"
+
+// workGroupOrDefault returns the workgroup of the config, or the default workgroup of athena
+func (c executionConfig) workGroupOrDefault() string {
+	if c.workGroup == "" {
+		return defaultWorkGroup
+	}
+	return c.workGroup
+}
+
+func (s *wrapperAthena) CreateNamedQuery(name string, description string, query string, opts ...Option) (string, error) {
+	conf := s.executionConfig(opts)
+	input := &athena.CreateNamedQueryInput{
+		Name:        aws.String(name),
+		Database:    aws.String(conf.database),
+		QueryString: aws.String(query),
+		WorkGroup:   aws.String(conf.workGroupOrDefault()),
+	}
+	if description != "" {
+		input.Description = aws.String(description)
+	}
+	if conf.clientRequestToken != "" {
+		input.ClientRequestToken = aws.String(conf.clientRequestToken)
+	}
+	output, err := s.Client.CreateNamedQuery(input)
+	if err != nil {
+		return "", errors.Wrap(err, fmt.Sprintf("Create named query failure name=%s", name))
+	}
+	return aws.StringValue(output.NamedQueryId), nil
+}
+
+func (s *wrapperAthena) GetNamedQuery(id string) (*athena.NamedQuery, error) {
+	output, err := s.Client.GetNamedQuery(&athena.GetNamedQueryInput{
+		NamedQueryId: aws.String(id),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("Get named query failure id=%s", id))
+	}
+	return output.NamedQuery, nil
+}
+
+func (s *wrapperAthena) ListNamedQueries(ctx context.Context, opts ...Option) ([]*athena.NamedQuery, error) {
+	input := &athena.ListNamedQueriesInput{
+		WorkGroup: aws.String(s.executionConfig(opts).workGroupOrDefault()),
+	}
+	queries := []*athena.NamedQuery{}
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		page, err := s.Client.ListNamedQueries(input)
+		if err != nil {
+			return nil, errors.Wrap(err, "List named queries failure")
+		}
+		if len(page.NamedQueryIds) > 0 {
+			output, err := s.Client.BatchGetNamedQuery(&athena.BatchGetNamedQueryInput{
+				NamedQueryIds: page.NamedQueryIds,
+			})
+			if err != nil {
+				return nil, errors.Wrap(err, "Batch get named queries failure")
+			}
+			queries = append(queries, output.NamedQueries...)
+		}
+		if aws.StringValue(page.NextToken) == "" {
+			return queries, nil
+		}
+		input.NextToken = page.NextToken
+	}
+}
+
+func (s *wrapperAthena) DeleteNamedQuery(id string) error {
+	_, err := s.Client.DeleteNamedQuery(&athena.DeleteNamedQueryInput{
+		NamedQueryId: aws.String(id),
+	})
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("Delete named query failure id=%s", id))
+	}
+	return nil
+}
+
+// ExecuteNamedQuery binds args into the named query like BindQuery and runs it
+// in the database and the workgroup of the named query.
+func (s *wrapperAthena) ExecuteNamedQuery(
+	id string, dest string, args ...interface{}) (*athena.StartQueryExecutionOutput, error) {
+
+	namedQuery, err := s.GetNamedQuery(id)
+	if err != nil {
+		return nil, err
+	}
+	query, err := BindQuery(aws.StringValue(namedQuery.QueryString), args...)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("Bind named query failure id=%s", id))
+	}
+	opts := []Option{WithDatabase(aws.StringValue(namedQuery.Database))}
+	if namedQuery.WorkGroup != nil {
+		opts = append(opts, WithWorkGroup(aws.StringValue(namedQuery.WorkGroup)))
+	}
+	return s.ExecuteQuery(query, dest, opts...)
+}
+
+func (s *wrapperAthena) PrepareStatement(name string, query string, opts ...Option) error {
+	if !isIdentifier(name) {
+		return errors.New(fmt.Sprintf("Invalid statement name name=%s", name))
+	}
+	_, err := s.Client.CreatePreparedStatement(&athena.CreatePreparedStatementInput{
+		StatementName:  aws.String(name),
+		QueryStatement: aws.String(query),
+		WorkGroup:      aws.String(s.executionConfig(opts).workGroupOrDefault()),
+	})
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("Create prepared statement failure name=%s", name))
+	}
+	return nil
+}
+
+func (s *wrapperAthena) GetPreparedStatement(name string, opts ...Option) (*athena.PreparedStatement, error) {
+	output, err := s.Client.GetPreparedStatement(&athena.GetPreparedStatementInput{
+		StatementName: aws.String(name),
+		WorkGroup:     aws.String(s.executionConfig(opts).workGroupOrDefault()),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("Get prepared statement failure name=%s", name))
+	}
+	return output.PreparedStatement, nil
+}
+
+func (s *wrapperAthena) ListPreparedStatements(
+	ctx context.Context, opts ...Option) ([]*athena.PreparedStatementSummary, error) {
+
+	input := &athena.ListPreparedStatementsInput{
+		WorkGroup: aws.String(s.executionConfig(opts).workGroupOrDefault()),
+	}
+	statements := []*athena.PreparedStatementSummary{}
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		page, err := s.Client.ListPreparedStatements(input)
+		if err != nil {
+			return nil, errors.Wrap(err, "List prepared statements failure")
+		}
+		statements = append(statements, page.PreparedStatements...)
+		if aws.StringValue(page.NextToken) == "" {
+			return statements, nil
+		}
+		input.NextToken = page.NextToken
+	}
+}
+
+func (s *wrapperAthena) DeletePreparedStatement(name string, opts ...Option) error {
+	_, err := s.Client.DeletePreparedStatement(&athena.DeletePreparedStatementInput{
+		StatementName: aws.String(name),
+		WorkGroup:     aws.String(s.executionConfig(opts).workGroupOrDefault()),
+	})
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("Delete prepared statement failure name=%s", name))
+	}
+	return nil
+}
+
+// ExecuteStatement runs the prepared statement by EXECUTE ... USING with the SQL literals of args.
+// The statement runs in the workgroup of the client options, where it must be prepared.
+// Use ExecuteStatementWithOptions to override the options.
+func (s *wrapperAthena) ExecuteStatement(
+	name string, dest string, args ...interface{}) (*athena.StartQueryExecutionOutput, error) {
+
+	return s.ExecuteStatementWithOptions(name, dest, args)
+}
+
+// ExecuteStatementWithOptions runs the prepared statement like ExecuteStatement with the options,
+// e.g. WithWorkGroup for the statement prepared in the other workgroup.
+func (s *wrapperAthena) ExecuteStatementWithOptions(
+	name string, dest string, args []interface{}, opts ...Option) (*athena.StartQueryExecutionOutput, error) {
+
+	if !isIdentifier(name) {
+		return nil, errors.New(fmt.Sprintf("Invalid statement name name=%s", name))
+	}
+	query := "EXECUTE " + name
+	if len(args) > 0 {
+		literals := make([]string, len(args))
+		for i, arg := range args {
+			literal, err := formatLiteral(arg)
+			if err != nil {
+				return nil, errors.Wrap(err, fmt.Sprintf("Bind argument failure index=%d", i))
+			}
+			literals[i] = literal
+		}
+		query += " USING " + strings.Join(literals, ", ")
+	}
+	return s.ExecuteQuery(query, dest, opts...)
+}
+
+func isIdentifier(name string) bool {
+	if name == "" || !isIdentStart(name[0]) {
+		return false
+	}
+	for i := 1; i < len(name); i++ {
+		if !isIdentPart(name[i]) {
+			return false
+		}
+	}
+	return true
+}
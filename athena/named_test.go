@@ -0,0 +1,105 @@
+package athena
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+func TestNamedQueries(t *testing.T) {
+	athenaMock := &AthenaMock{}
+	athenaWrapper := New(athenaMock, "test-db", WithWorkGroup("test-workgroup"))
+
+	id, err := athenaWrapper.CreateNamedQuery("daily", "daily report", "SELECT * FROM t WHERE dt = :dt")
+	if err != nil {
+		t.Fatalf("Create named query failure %s", err.Error())
+	}
+	if _, err := athenaWrapper.CreateNamedQuery("weekly", "", "SELECT 1"); err != nil {
+		t.Fatalf("Create named query failure %s", err.Error())
+	}
+
+	namedQuery, err := athenaWrapper.GetNamedQuery(id)
+	if err != nil {
+		t.Fatalf("Get named query failure %s", err.Error())
+	}
+	if aws.StringValue(namedQuery.Name) != "daily" || aws.StringValue(namedQuery.WorkGroup) != "test-workgroup" {
+		t.Fatalf("Wrong named query %+v", namedQuery)
+	}
+
+	namedQueries, err := athenaWrapper.ListNamedQueries(context.Background())
+	if err != nil {
+		t.Fatalf("List named queries failure %s", err.Error())
+	}
+	if len(namedQueries) != 2 {
+		t.Fatalf("Wrong named queries length %d", len(namedQueries))
+	}
+
+	if _, err := athenaWrapper.ExecuteNamedQuery(id, "s3://test-bucket", NamedParams{"dt": "2019-12-01"}); err != nil {
+		t.Fatalf("Execute named query failure %s", err.Error())
+	}
+	input := athenaMock.StartQueryExecutionInput
+	if aws.StringValue(input.QueryString) != "SELECT * FROM t WHERE dt = '2019-12-01'" {
+		t.Fatalf("Wrong query string %s", aws.StringValue(input.QueryString))
+	}
+	if aws.StringValue(input.QueryExecutionContext.Database) != "test-db" || aws.StringValue(input.WorkGroup) != "test-workgroup" {
+		t.Fatalf("Named query must run in its database and workgroup %+v", input)
+	}
+
+	if err := athenaWrapper.DeleteNamedQuery(id); err != nil {
+		t.Fatalf("Delete named query failure %s", err.Error())
+	}
+	if _, err := athenaWrapper.GetNamedQuery(id); err == nil {
+		t.Fatalf("Deleted named query must not be found")
+	}
+}
+
+func TestPreparedStatements(t *testing.T) {
+	athenaMock := &AthenaMock{}
+	athenaWrapper := New(athenaMock, "test-db")
+
+	if err := athenaWrapper.PrepareStatement("by_id", "SELECT * FROM t WHERE id = ? AND name = ?"); err != nil {
+		t.Fatalf("Prepare statement failure %s", err.Error())
+	}
+	statement, err := athenaWrapper.GetPreparedStatement("by_id")
+	if err != nil {
+		t.Fatalf("Get prepared statement failure %s", err.Error())
+	}
+	if aws.StringValue(statement.WorkGroupName) != "primary" {
+		t.Fatalf("Statement must be prepared in the default workgroup %+v", statement)
+	}
+
+	statements, err := athenaWrapper.ListPreparedStatements(context.Background())
+	if err != nil {
+		t.Fatalf("List prepared statements failure %s", err.Error())
+	}
+	if len(statements) != 1 || aws.StringValue(statements[0].StatementName) != "by_id" {
+		t.Fatalf("Wrong prepared statements %+v", statements)
+	}
+
+	if _, err := athenaWrapper.ExecuteStatement("by_id", "s3://test-bucket", 10, "it's"); err != nil {
+		t.Fatalf("Execute statement failure %s", err.Error())
+	}
+	if aws.StringValue(athenaMock.StartQueryExecutionInput.QueryString) != "EXECUTE by_id USING 10, 'it''s'" {
+		t.Fatalf("Wrong query string %s", aws.StringValue(athenaMock.StartQueryExecutionInput.QueryString))
+	}
+	if _, err := athenaWrapper.ExecuteStatement("by_id; DROP TABLE t", "s3://test-bucket"); err == nil {
+		t.Fatalf("Invalid statement name must be rejected")
+	}
+	_, err = athenaWrapper.ExecuteStatementWithOptions("by_id", "s3://test-bucket", []interface{}{1, "foo"},
+		WithWorkGroup("other-workgroup"))
+	if err != nil {
+		t.Fatalf("Execute statement failure %s", err.Error())
+	}
+	input := athenaMock.StartQueryExecutionInput
+	if aws.StringValue(input.QueryString) != "EXECUTE by_id USING 1, 'foo'" || aws.StringValue(input.WorkGroup) != "other-workgroup" {
+		t.Fatalf("Wrong execution input %+v", input)
+	}
+
+	if err := athenaWrapper.DeletePreparedStatement("by_id"); err != nil {
+		t.Fatalf("Delete prepared statement failure %s", err.Error())
+	}
+	if _, err := athenaWrapper.GetPreparedStatement("by_id"); err == nil {
+		t.Fatalf("Deleted statement must not be found")
+	}
+}
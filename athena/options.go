@@ -0,0 +1,140 @@
+package athena
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+)
+
+// Option configures query executions. Options passed to New are the defaults of the client,
+// and options passed to ExecuteQuery or RunOptions override them for the call.
+type Option func(*executionConfig)
+
+type executionConfig struct {
+	database            string
+	catalog             string
+	workGroup           string
+	encryptionOption    string
+	kmsKey              string
+	clientRequestToken  string
+	expectedBucketOwner string
+	resultReuseMaxAge   time.Duration
+	pricePerTB          float64
+	statsHook           func(*QueryStats)
+	cache               ResultCache
+	cacheTTL            time.Duration
+	cacheBypass         bool
+}
+
+// WithDatabase overrides the database given to New
+func WithDatabase(name string) Option {
+	return func(c *executionConfig) {
+		c.database = name
+	}
+}
+
+// WithCatalog sets the data catalog of the database
+func WithCatalog(name string) Option {
+	return func(c *executionConfig) {
+		c.catalog = name
+	}
+}
+
+// WithWorkGroup sets the workgroup which runs the query
+func WithWorkGroup(name string) Option {
+	return func(c *executionConfig) {
+		c.workGroup = name
+	}
+}
+
+// WithEncryption encrypts the results. option is one of athena.EncryptionOption* values,
+// and kmsKey is the KMS key ARN or ID which is required by SSE_KMS and CSE_KMS.
+func WithEncryption(option string, kmsKey string) Option {
+	return func(c *executionConfig) {
+		c.encryptionOption = option
+		c.kmsKey = kmsKey
+	}
+}
+
+// WithClientRequestToken makes the execution idempotent.
+// Starting a query with the same token returns the execution which is already started.
+// The token is per call only, and it is ignored as an option of New which starts every query.
+func WithClientRequestToken(token string) Option {
+	return func(c *executionConfig) {
+		c.clientRequestToken = token
+	}
+}
+
+// WithExpectedBucketOwner sets the AWS account ID which must own the output bucket
+func WithExpectedBucketOwner(accountID string) Option {
+	return func(c *executionConfig) {
+		c.expectedBucketOwner = accountID
+	}
+}
+
+// WithResultReuse reuses the results of the same query which ran within maxAge.
+// maxAge is rounded up to minutes, and zero disables the reuse.
+func WithResultReuse(maxAge time.Duration) Option {
+	return func(c *executionConfig) {
+		c.resultReuseMaxAge = maxAge
+	}
+}
+
+// executionConfig returns the config of the client defaults overridden by opts
+func (s *wrapperAthena) executionConfig(opts []Option) executionConfig {
+	conf := executionConfig{database: s.DatabaseName, pricePerTB: DefaultPricePerTB}
+	for _, opt := range s.Options {
+		opt(&conf)
+	}
+	conf.clientRequestToken = ""
+	for _, opt := range opts {
+		opt(&conf)
+	}
+	return conf
+}
+
+// startQueryExecutionInput builds the input of StartQueryExecution from the config
+func (c executionConfig) startQueryExecutionInput(query string, dest string) *athena.StartQueryExecutionInput {
+	input := &athena.StartQueryExecutionInput{
+		QueryExecutionContext: &athena.QueryExecutionContext{},
+		QueryString:           aws.String(query),
+		ResultConfiguration:   &athena.ResultConfiguration{},
+	}
+	if c.database != "" {
+		input.QueryExecutionContext.Database = aws.String(c.database)
+	}
+	if c.catalog != "" {
+		input.QueryExecutionContext.Catalog = aws.String(c.catalog)
+	}
+	// The output location of the workgroup is used when dest is empty
+	if dest != "" {
+		input.ResultConfiguration.OutputLocation = aws.String(dest)
+	}
+	if c.workGroup != "" {
+		input.WorkGroup = aws.String(c.workGroup)
+	}
+	if c.encryptionOption != "" {
+		input.ResultConfiguration.EncryptionConfiguration = &athena.EncryptionConfiguration{
+			EncryptionOption: aws.String(c.encryptionOption),
+		}
+		if c.kmsKey != "" {
+			input.ResultConfiguration.EncryptionConfiguration.KmsKey = aws.String(c.kmsKey)
+		}
+	}
+	if c.expectedBucketOwner != "" {
+		input.ResultConfiguration.ExpectedBucketOwner = aws.String(c.expectedBucketOwner)
+	}
+	if c.clientRequestToken != "" {
+		input.ClientRequestToken = aws.String(c.clientRequestToken)
+	}
+	if c.resultReuseMaxAge > 0 {
+		input.ResultReuseConfiguration = &athena.ResultReuseConfiguration{
+			ResultReuseByAgeConfiguration: &athena.ResultReuseByAgeConfiguration{
+				Enabled:         aws.Bool(true),
+				MaxAgeInMinutes: aws.Int64(int64((c.resultReuseMaxAge + time.Minute - 1) / time.Minute)),
+			},
+		}
+	}
+	return input
+}
@@ -0,0 +1,100 @@
+package athena
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+)
+
+func TestExecuteQueryClientOptions(t *testing.T) {
+	athenaMock := &AthenaMock{}
+	athenaWrapper := New(athenaMock, "test-db",
+		WithWorkGroup("test-workgroup"),
+		WithCatalog("test-catalog"),
+		WithEncryption(athena.EncryptionOptionSseKms, "test-key"),
+		WithExpectedBucketOwner("123456789012"),
+		WithResultReuse(90*time.Second),
+	)
+	if _, err := athenaWrapper.ExecuteQuery("dummyQuery", "s3://test-bucket"); err != nil {
+		t.Fatalf("Execute failure %s", err.Error())
+	}
+
+	input := athenaMock.StartQueryExecutionInput
+	if aws.StringValue(input.WorkGroup) != "test-workgroup" {
+		t.Fatalf("Wrong workgroup %s", aws.StringValue(input.WorkGroup))
+	}
+	if aws.StringValue(input.QueryExecutionContext.Database) != "test-db" ||
+		aws.StringValue(input.QueryExecutionContext.Catalog) != "test-catalog" {
+		t.Fatalf("Wrong execution context %+v", input.QueryExecutionContext)
+	}
+	encryption := input.ResultConfiguration.EncryptionConfiguration
+	if aws.StringValue(encryption.EncryptionOption) != "SSE_KMS" || aws.StringValue(encryption.KmsKey) != "test-key" {
+		t.Fatalf("Wrong encryption configuration %+v", encryption)
+	}
+	if aws.StringValue(input.ResultConfiguration.ExpectedBucketOwner) != "123456789012" {
+		t.Fatalf("Wrong expected bucket owner %+v", input.ResultConfiguration)
+	}
+	if aws.Int64Value(input.ResultReuseConfiguration.ResultReuseByAgeConfiguration.MaxAgeInMinutes) != 2 {
+		t.Fatalf("Wrong result reuse configuration %+v", input.ResultReuseConfiguration)
+	}
+	if input.ClientRequestToken != nil {
+		t.Fatalf("Client request token must not be set %s", aws.StringValue(input.ClientRequestToken))
+	}
+}
+
+func TestExecuteQueryCallOptions(t *testing.T) {
+	athenaMock := &AthenaMock{}
+	athenaWrapper := New(athenaMock, "test-db", WithWorkGroup("test-workgroup"))
+	_, err := athenaWrapper.ExecuteQuery("dummyQuery", "",
+		WithWorkGroup("other-workgroup"),
+		WithDatabase("other-db"),
+		WithClientRequestToken("dummyToken"),
+	)
+	if err != nil {
+		t.Fatalf("Execute failure %s", err.Error())
+	}
+
+	input := athenaMock.StartQueryExecutionInput
+	if aws.StringValue(input.WorkGroup) != "other-workgroup" {
+		t.Fatalf("Wrong workgroup %s", aws.StringValue(input.WorkGroup))
+	}
+	if aws.StringValue(input.QueryExecutionContext.Database) != "other-db" {
+		t.Fatalf("Wrong database %s", aws.StringValue(input.QueryExecutionContext.Database))
+	}
+	if aws.StringValue(input.ClientRequestToken) != "dummyToken" {
+		t.Fatalf("Wrong client request token %s", aws.StringValue(input.ClientRequestToken))
+	}
+	if input.ResultConfiguration.OutputLocation != nil {
+		t.Fatalf("Empty dest must leave the output location to the workgroup")
+	}
+
+	if _, err := athenaWrapper.ExecuteQuery("dummyQuery", ""); err != nil {
+		t.Fatalf("Execute failure %s", err.Error())
+	}
+	if aws.StringValue(athenaMock.StartQueryExecutionInput.WorkGroup) != "test-workgroup" {
+		t.Fatalf("Call options must not change the client defaults")
+	}
+}
+
+func TestClientRequestTokenIsPerCall(t *testing.T) {
+	athenaMock := &AthenaMock{}
+	athenaWrapper := New(athenaMock, "test-db", WithClientRequestToken("defaultToken"))
+	for i := 0; i < 2; i++ {
+		if _, err := athenaWrapper.ExecuteQuery("dummyQuery", ""); err != nil {
+			t.Fatalf("Execute failure %s", err.Error())
+		}
+		if athenaMock.StartQueryExecutionInput.ClientRequestToken != nil {
+			t.Fatalf("Client request token of New must be ignored %s",
+				aws.StringValue(athenaMock.StartQueryExecutionInput.ClientRequestToken))
+		}
+	}
+
+	if _, err := athenaWrapper.ExecuteQuery("dummyQuery", "", WithClientRequestToken("callToken")); err != nil {
+		t.Fatalf("Execute failure %s", err.Error())
+	}
+	if aws.StringValue(athenaMock.StartQueryExecutionInput.ClientRequestToken) != "callToken" {
+		t.Fatalf("Wrong client request token %s", aws.StringValue(athenaMock.StartQueryExecutionInput.ClientRequestToken))
+	}
+}
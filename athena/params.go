@@ -0,0 +1,264 @@
+package athena
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/athena"
+	"github.com/pkg/errors"
+)
+
+const timestampLiteralLayout = "2006-01-02 15:04:05.000"
+
+// NamedParams is values of named placeholders like :name.
+// Pass it as the only argument of BindQuery to use named placeholders.
+type NamedParams map[string]interface{}
+
+// Date is a value formatted as DATE literal instead of TIMESTAMP
+type Date struct {
+	time.Time
+}
+
+// AsDate returns the date part of t as Date
+func AsDate(t time.Time) Date {
+	return Date{t}
+}
+
+// BindQuery replaces the placeholders of the query with SQL literals of args.
+// Positional placeholders are ?, and named placeholders like :name are used when the only
+// argument is NamedParams. Placeholders in string literals, quoted identifiers and comments
+// are left as they are.
+//
+// Supported values are nil, string, bool, integers, floats, []byte, time.Time (as TIMESTAMP
+// in UTC), Date, pointers to them, and slices and maps of them (as ARRAY and MAP).
+// Other types are rejected.
+func BindQuery(query string, args ...interface{}) (string, error) {
+	if len(args) == 1 {
+		if params, ok := args[0].(NamedParams); ok {
+			return bindNamed(query, params)
+		}
+	}
+	return bindPositional(query, args)
+}
+
+func bindPositional(query string, args []interface{}) (string, error) {
+	var b strings.Builder
+	index := 0
+	err := scanQuery(query, &b, func(i int) (int, error) {
+		if query[i] != '?' {
+			return 0, nil
+		}
+		index++
+		if index > len(args) {
+			return 1, nil
+		}
+		literal, err := formatLiteral(args[index-1])
+		if err != nil {
+			return 0, errors.Wrap(err, fmt.Sprintf("Bind argument failure index=%d", index-1))
+		}
+		b.WriteString(literal)
+		return 1, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if index != len(args) {
+		return "", errors.New(fmt.Sprintf("Wrong number of arguments placeholders=%d args=%d", index, len(args)))
+	}
+	return b.String(), nil
+}
+
+func bindNamed(query string, params NamedParams) (string, error) {
+	var b strings.Builder
+	err := scanQuery(query, &b, func(i int) (int, error) {
+		if query[i] != ':' || i+1 >= len(query) || !isIdentStart(query[i+1]) || (i > 0 && query[i-1] == ':') {
+			return 0, nil
+		}
+		end := i + 1
+		for end < len(query) && isIdentPart(query[end]) {
+			end++
+		}
+		name := query[i+1 : end]
+		value, ok := params[name]
+		if !ok {
+			return 0, errors.New(fmt.Sprintf("Missing named parameter name=%s", name))
+		}
+		literal, err := formatLiteral(value)
+		if err != nil {
+			return 0, errors.Wrap(err, fmt.Sprintf("Bind parameter failure name=%s", name))
+		}
+		b.WriteString(literal)
+		return end - i, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// scanQuery copies the query into b, and calls replace for every character outside of
+// string literals, quoted identifiers and comments. replace returns the number of
+// characters it consumed, or 0 to copy the character as it is.
+func scanQuery(query string, b *strings.Builder, replace func(i int) (int, error)) error {
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"':
+			end := i + 1
+			for end < len(query) {
+				if query[end] == c {
+					if end+1 < len(query) && query[end+1] == c {
+						end += 2
+						continue
+					}
+					break
+				}
+				end++
+			}
+			if end >= len(query) {
+				return errors.New("Unterminated quote in query")
+			}
+			b.WriteString(query[i : end+1])
+			i = end + 1
+		case strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i
+			}
+			b.WriteString(query[i : i+end])
+			i += end
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return errors.New("Unterminated comment in query")
+			}
+			b.WriteString(query[i : i+end+4])
+			i += end + 4
+		default:
+			n, err := replace(i)
+			if err != nil {
+				return err
+			}
+			if n > 0 {
+				i += n
+				continue
+			}
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return nil
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9')
+}
+
+// quoteString returns the string literal of s
+func quoteString(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
+// formatLiteral returns the SQL literal of the value
+func formatLiteral(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "NULL", nil
+	case string:
+		return quoteString(v), nil
+	case []byte:
+		return "X'" + strings.ToUpper(hex.EncodeToString(v)) + "'", nil
+	case time.Time:
+		return "TIMESTAMP " + quoteString(v.UTC().Format(timestampLiteralLayout)), nil
+	case Date:
+		return "DATE " + quoteString(v.Format("2006-01-02")), nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return "NULL", nil
+		}
+		return formatLiteral(rv.Elem().Interface())
+	case reflect.String:
+		return quoteString(rv.String()), nil
+	case reflect.Bool:
+		if rv.Bool() {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return "", errors.New(fmt.Sprintf("Unsupported float value=%f", f))
+		}
+		// The exponent makes the literal DOUBLE instead of DECIMAL
+		return strconv.FormatFloat(f, 'E', -1, 64), nil
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return "NULL", nil
+		}
+		elements := make([]string, rv.Len())
+		for i := range elements {
+			literal, err := formatLiteral(rv.Index(i).Interface())
+			if err != nil {
+				return "", err
+			}
+			elements[i] = literal
+		}
+		return "ARRAY[" + strings.Join(elements, ", ") + "]", nil
+	case reflect.Map:
+		if rv.IsNil() {
+			return "NULL", nil
+		}
+		keys := make([]string, 0, rv.Len())
+		values := make([]string, 0, rv.Len())
+		entries := rv.MapKeys()
+		literals := make(map[string]string, len(entries))
+		for _, key := range entries {
+			keyLiteral, err := formatLiteral(key.Interface())
+			if err != nil {
+				return "", err
+			}
+			valueLiteral, err := formatLiteral(rv.MapIndex(key).Interface())
+			if err != nil {
+				return "", err
+			}
+			literals[keyLiteral] = valueLiteral
+			keys = append(keys, keyLiteral)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			values = append(values, literals[key])
+		}
+		return "MAP(ARRAY[" + strings.Join(keys, ", ") + "], ARRAY[" + strings.Join(values, ", ") + "])", nil
+	}
+	return "", errors.New(fmt.Sprintf("Unsupported parameter type=%T", value))
+}
+
+// ExecuteQueryWithArgs runs the query with the default options of the client.
+// Use BindQuery and ExecuteQuery to override the options.
+func (s *wrapperAthena) ExecuteQueryWithArgs(
+	query string, dest string, args ...interface{}) (*athena.StartQueryExecutionOutput, error) {
+
+	bound, err := BindQuery(query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Bind query arguments failure")
+	}
+	return s.ExecuteQuery(bound, dest)
+}
@@ -0,0 +1,82 @@
+package athena
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBindQueryPositional(t *testing.T) {
+	createdAt := time.Date(2019, 12, 1, 10, 20, 30, 123000000, time.UTC)
+	query, err := BindQuery(
+		"SELECT * FROM t WHERE name = ? AND note = '?' AND id IN (?) AND created_at > ? AND day = ? -- ?\nAND score > ?",
+		"it's", []int{1, 2}, createdAt, AsDate(createdAt), 1.5)
+	if err != nil {
+		t.Fatalf("Bind query failure %s", err.Error())
+	}
+	expected := "SELECT * FROM t WHERE name = 'it''s' AND note = '?' AND id IN (ARRAY[1, 2]) " +
+		"AND created_at > TIMESTAMP '2019-12-01 10:20:30.123' AND day = DATE '2019-12-01' -- ?\nAND score > 1.5E+00"
+	if query != expected {
+		t.Fatalf("Wrong query. Expected %s but %s", expected, query)
+	}
+}
+
+func TestBindQueryNamed(t *testing.T) {
+	var missing *string
+	query, err := BindQuery(
+		`SELECT ":id" FROM t WHERE id = :id AND tenant = :tenant AND flag = :flag AND note = :note`,
+		NamedParams{"id": int64(10), "tenant": "a", "flag": true, "note": missing})
+	if err != nil {
+		t.Fatalf("Bind query failure %s", err.Error())
+	}
+	expected := `SELECT ":id" FROM t WHERE id = 10 AND tenant = 'a' AND flag = TRUE AND note = NULL`
+	if query != expected {
+		t.Fatalf("Wrong query. Expected %s but %s", expected, query)
+	}
+}
+
+func TestBindQueryMap(t *testing.T) {
+	query, err := BindQuery("SELECT ?", map[string]int{"b": 2, "a": 1})
+	if err != nil {
+		t.Fatalf("Bind query failure %s", err.Error())
+	}
+	if query != "SELECT MAP(ARRAY['a', 'b'], ARRAY[1, 2])" {
+		t.Fatalf("Wrong query %s", query)
+	}
+}
+
+func TestBindQueryErrors(t *testing.T) {
+	cases := []struct {
+		query string
+		args  []interface{}
+	}{
+		{"SELECT ?, ?", []interface{}{1}},
+		{"SELECT ?", []interface{}{1, 2}},
+		{"SELECT ?", []interface{}{struct{}{}}},
+		{"SELECT :missing", []interface{}{NamedParams{}}},
+		{"SELECT 'unterminated ?", []interface{}{1}},
+	}
+	for _, c := range cases {
+		if _, err := BindQuery(c.query, c.args...); err == nil {
+			t.Fatalf("Bind query must fail query=%s args=%+v", c.query, c.args)
+		}
+	}
+}
+
+func TestExecuteQueryWithArgs(t *testing.T) {
+	athenaMock := &AthenaMock{}
+	athenaWrapper := New(athenaMock, "test-db")
+	result, err := athenaWrapper.ExecuteQueryWithArgs("SELECT * FROM t WHERE id = ?", "test-dest", 1)
+	if err != nil {
+		t.Fatalf("Execute failure %s", err.Error())
+	}
+	if *result.QueryExecutionId != "dummyQueryID" {
+		t.Fatalf("Wrong execution id: %s", *result.QueryExecutionId)
+	}
+	if *athenaMock.StartQueryExecutionInput.QueryString != "SELECT * FROM t WHERE id = 1" {
+		t.Fatalf("Wrong query string: %s", *athenaMock.StartQueryExecutionInput.QueryString)
+	}
+
+	if _, err := athenaWrapper.ExecuteQueryWithArgs("SELECT ?", "test-dest", make(chan int)); err == nil {
+		t.Fatalf("Unsupported argument must be rejected")
+	}
+}
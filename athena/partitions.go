@@ -0,0 +1,211 @@
+package athena
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/pkg/errors"
+)
+
+// MaxQueryLength is the maximum length in bytes of a query string athena accepts
+const MaxQueryLength = 262144
+
+// PartitionValue is a pair of partition key and its value
+type PartitionValue struct {
+	Key   string
+	Value string
+}
+
+// Partition is the values of the partition keys in the order of the table definition
+type Partition []PartitionValue
+
+// Path returns the hive style path of the partition like dt=2020-01-01/tenant=a
+func (p Partition) Path() string {
+	parts := make([]string, len(p))
+	for i, value := range p {
+		parts[i] = value.Key + "=" + url.PathEscape(value.Value)
+	}
+	return strings.Join(parts, "/")
+}
+
+// Location returns the location template whose {key} placeholders are replaced with the values
+func (p Partition) Location(template string) string {
+	pairs := make([]string, 0, len(p)*2)
+	for _, value := range p {
+		pairs = append(pairs, "{"+value.Key+"}", value.Value)
+	}
+	return strings.NewReplacer(pairs...).Replace(template)
+}
+
+// spec returns the partition spec like PARTITION (`dt` = '2020-01-01')
+func (p Partition) spec() (string, error) {
+	if len(p) == 0 {
+		return "", errors.New("Partition must have at least one key")
+	}
+	specs := make([]string, len(p))
+	for i, value := range p {
+		if value.Key == "" {
+			return "", errors.New(fmt.Sprintf("Empty partition key partition=%s", p.Path()))
+		}
+		specs[i] = quoteIdent(value.Key) + " = " + quoteString(value.Value)
+	}
+	return "PARTITION (" + strings.Join(specs, ", ") + ")", nil
+}
+
+// PartitionOptions is options of managing partitions
+type PartitionOptions struct {
+	RunOptions
+	// LocationTemplate is the s3 location of added partitions whose {key} placeholders are replaced
+	// with the partition values, e.g. s3://bucket/table/dt={dt}/. Empty uses the default location of the table.
+	LocationTemplate string
+	// MaxQueryLength is the maximum length of one statement. Default is MaxQueryLength.
+	MaxQueryLength int
+}
+
+func (o *PartitionOptions) maxQueryLength() int {
+	if o.MaxQueryLength <= 0 || o.MaxQueryLength > MaxQueryLength {
+		return MaxQueryLength
+	}
+	return o.MaxQueryLength
+}
+
+// quoteIdent quotes the identifier, or the parts of database.table, with backquotes for DDL
+func quoteIdent(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = "`" + strings.Replace(part, "`", "``", -1) + "`"
+	}
+	return strings.Join(parts, ".")
+}
+
+// chunkStatements joins the clauses after the header into statements which are not longer than maxLength
+func chunkStatements(header string, clauses []string, separator string, maxLength int) ([]string, error) {
+	statements := []string{}
+	current := ""
+	for _, clause := range clauses {
+		if len(header)+len(clause) > maxLength {
+			return nil, errors.New(fmt.Sprintf("Clause exceeds max query length clause=%s", clause))
+		}
+		if current != "" && len(current)+len(separator)+len(clause) > maxLength {
+			statements = append(statements, current)
+			current = ""
+		}
+		if current == "" {
+			current = header + clause
+			continue
+		}
+		current += separator + clause
+	}
+	if current != "" {
+		statements = append(statements, current)
+	}
+	return statements, nil
+}
+
+// runStatements runs the statements one by one and waits for every completion
+func (s *wrapperAthena) runStatements(ctx context.Context, statements []string, opts *RunOptions) error {
+	for i, statement := range statements {
+		result, err := s.RunQuery(ctx, statement, opts)
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("Run statement failure statement=%d/%d", i+1, len(statements)))
+		}
+		result.Rows.Close()
+	}
+	return nil
+}
+
+func (s *wrapperAthena) AddPartitions(
+	ctx context.Context, table string, partitions []Partition, opts *PartitionOptions) error {
+
+	if opts == nil {
+		opts = &PartitionOptions{}
+	}
+	clauses := make([]string, len(partitions))
+	for i, partition := range partitions {
+		spec, err := partition.spec()
+		if err != nil {
+			return err
+		}
+		clauses[i] = spec
+		if opts.LocationTemplate != "" {
+			clauses[i] += " LOCATION " + quoteString(partition.Location(opts.LocationTemplate))
+		}
+	}
+	header := "ALTER TABLE " + quoteIdent(table) + " ADD IF NOT EXISTS "
+	statements, err := chunkStatements(header, clauses, " ", opts.maxQueryLength())
+	if err != nil {
+		return err
+	}
+	return s.runStatements(ctx, statements, &opts.RunOptions)
+}
+
+func (s *wrapperAthena) DropPartitions(
+	ctx context.Context, table string, partitions []Partition, opts *PartitionOptions) error {
+
+	if opts == nil {
+		opts = &PartitionOptions{}
+	}
+	clauses := make([]string, len(partitions))
+	for i, partition := range partitions {
+		spec, err := partition.spec()
+		if err != nil {
+			return err
+		}
+		clauses[i] = spec
+	}
+	header := "ALTER TABLE " + quoteIdent(table) + " DROP IF EXISTS "
+	statements, err := chunkStatements(header, clauses, ", ", opts.maxQueryLength())
+	if err != nil {
+		return err
+	}
+	return s.runStatements(ctx, statements, &opts.RunOptions)
+}
+
+func (s *wrapperAthena) ListPartitions(ctx context.Context, table string, opts *RunOptions) ([]Partition, error) {
+	result, err := s.RunQuery(ctx, "SHOW PARTITIONS "+quoteIdent(table), opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "Show partitions failure")
+	}
+	defer result.Rows.Close()
+
+	partitions := []Partition{}
+	for result.Rows.Next() {
+		row := result.Rows.Row()
+		if len(row.Data) == 0 {
+			continue
+		}
+		partition, err := parsePartition(aws.StringValue(row.Data[0].VarCharValue))
+		if err != nil {
+			return nil, err
+		}
+		partitions = append(partitions, partition)
+	}
+	if err := result.Rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "Read partitions failure")
+	}
+	return partitions, nil
+}
+
+func (s *wrapperAthena) RepairTable(ctx context.Context, table string, opts *RunOptions) error {
+	return s.runStatements(ctx, []string{"MSCK REPAIR TABLE " + quoteIdent(table)}, opts)
+}
+
+// parsePartition parses the hive style path of SHOW PARTITIONS output
+func parsePartition(path string) (Partition, error) {
+	partition := Partition{}
+	for _, part := range strings.Split(path, "/") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, errors.New(fmt.Sprintf("Invalid partition path=%s", path))
+		}
+		value, err := url.PathUnescape(kv[1])
+		if err != nil {
+			value = kv[1]
+		}
+		partition = append(partition, PartitionValue{Key: kv[0], Value: value})
+	}
+	return partition, nil
+}
@@ -0,0 +1,116 @@
+package athena
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+)
+
+func makePartitions(n int) []Partition {
+	partitions := []Partition{}
+	for i := 0; i < n; i++ {
+		partitions = append(partitions, Partition{
+			{Key: "dt", Value: fmt.Sprintf("2019-12-%02d", i+1)},
+			{Key: "tenant", Value: "a"},
+		})
+	}
+	return partitions
+}
+
+func TestAddPartitions(t *testing.T) {
+	athenaMock := &AthenaMock{
+		GetQueryExecutionOutputCh: makeStateCh("SUCCEEDED", "SUCCEEDED"),
+		ResultPages:               makeResultPages(makeColumns("result", "varchar")),
+	}
+	athenaWrapper := New(athenaMock, "test-db")
+
+	opts := &PartitionOptions{
+		RunOptions:       RunOptions{Wait: &WaitOptions{InitialInterval: time.Millisecond}},
+		LocationTemplate: "s3://test-bucket/logs/dt={dt}/tenant={tenant}/",
+		MaxQueryLength:   300,
+	}
+	if err := athenaWrapper.AddPartitions(context.Background(), "test-db.logs", makePartitions(3), opts); err != nil {
+		t.Fatalf("Add partitions failure %s", err.Error())
+	}
+	if len(athenaMock.QueryStrings) != 2 {
+		t.Fatalf("Partitions must be split into 2 statements %+v", athenaMock.QueryStrings)
+	}
+	expected := "ALTER TABLE `test-db`.`logs` ADD IF NOT EXISTS " +
+		"PARTITION (`dt` = '2019-12-01', `tenant` = 'a') LOCATION 's3://test-bucket/logs/dt=2019-12-01/tenant=a/' " +
+		"PARTITION (`dt` = '2019-12-02', `tenant` = 'a') LOCATION 's3://test-bucket/logs/dt=2019-12-02/tenant=a/'"
+	if athenaMock.QueryStrings[0] != expected {
+		t.Fatalf("Wrong statement %s", athenaMock.QueryStrings[0])
+	}
+	for _, query := range athenaMock.QueryStrings {
+		if len(query) > 300 {
+			t.Fatalf("Statement exceeds max query length %d", len(query))
+		}
+	}
+}
+
+func TestDropPartitions(t *testing.T) {
+	athenaMock := &AthenaMock{
+		GetQueryExecutionOutputCh: makeStateCh("SUCCEEDED"),
+		ResultPages:               makeResultPages(makeColumns("result", "varchar")),
+	}
+	athenaWrapper := New(athenaMock, "test-db")
+
+	opts := &PartitionOptions{RunOptions: RunOptions{Wait: &WaitOptions{InitialInterval: time.Millisecond}}}
+	if err := athenaWrapper.DropPartitions(context.Background(), "logs", makePartitions(2), opts); err != nil {
+		t.Fatalf("Drop partitions failure %s", err.Error())
+	}
+	expected := "ALTER TABLE `logs` DROP IF EXISTS " +
+		"PARTITION (`dt` = '2019-12-01', `tenant` = 'a'), PARTITION (`dt` = '2019-12-02', `tenant` = 'a')"
+	if len(athenaMock.QueryStrings) != 1 || athenaMock.QueryStrings[0] != expected {
+		t.Fatalf("Wrong statements %+v", athenaMock.QueryStrings)
+	}
+}
+
+func TestListPartitions(t *testing.T) {
+	athenaMock := &AthenaMock{
+		GetQueryExecutionOutputCh: makeStateCh("SUCCEEDED"),
+		ResultPages: []*athena.GetQueryResultsOutput{
+			{
+				ResultSet: &athena.ResultSet{
+					ResultSetMetadata: &athena.ResultSetMetadata{ColumnInfo: makeColumns("partition", "string")},
+					Rows: []*athena.Row{
+						makeRow(aws.String("dt=2019-12-01/tenant=a")),
+						makeRow(aws.String("dt=2019-12-02/tenant=b%2Fc")),
+					},
+				},
+			},
+		},
+	}
+	athenaWrapper := New(athenaMock, "test-db")
+
+	partitions, err := athenaWrapper.ListPartitions(context.Background(), "logs", &RunOptions{
+		Wait: &WaitOptions{InitialInterval: time.Millisecond},
+	})
+	if err != nil {
+		t.Fatalf("List partitions failure %s", err.Error())
+	}
+	if athenaMock.QueryStrings[0] != "SHOW PARTITIONS `logs`" {
+		t.Fatalf("Wrong statement %s", athenaMock.QueryStrings[0])
+	}
+	if len(partitions) != 2 {
+		t.Fatalf("Wrong partitions length %d", len(partitions))
+	}
+	if partitions[1][1].Key != "tenant" || partitions[1][1].Value != "b/c" {
+		t.Fatalf("Wrong partition %+v", partitions[1])
+	}
+	if partitions[0].Path() != "dt=2019-12-01/tenant=a" {
+		t.Fatalf("Wrong path %s", partitions[0].Path())
+	}
+}
+
+func TestChunkStatementsTooLongClause(t *testing.T) {
+	_, err := chunkStatements("ALTER TABLE t ADD ", []string{strings.Repeat("x", 100)}, " ", 50)
+	if err == nil {
+		t.Fatalf("Too long clause must be rejected")
+	}
+}
@@ -0,0 +1,84 @@
+package athena
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+	"github.com/pkg/errors"
+)
+
+// RunOptions is options of RunQuery
+type RunOptions struct {
+	// OutputLocation is the s3 path where athena writes the results
+	OutputLocation string
+	// Wait is polling policy of waiting for the query. Nil uses the default policy.
+	Wait *WaitOptions
+	// MaxResults is page size of fetching results. Default is 1000.
+	MaxResults int
+	// Execution overrides the execution options of the client
+	Execution []Option
+}
+
+// QueryResult is the result of a succeeded query execution
+type QueryResult struct {
+	JobID string
+	// OutputLocation is the s3 path of the result file
+	OutputLocation string
+	// DataScannedBytes is the number of bytes the query scanned
+	DataScannedBytes int64
+	// ExecutionTime is the time the query engine took to execute the query
+	ExecutionTime time.Duration
+	// Stats is the statistics of the execution
+	Stats *QueryStats
+	// Rows iterates over the rows of the results. Close it when the rows are no longer needed.
+	Rows *RowIterator
+}
+
+// RowCount returns the number of rows read through Rows so far.
+// Athena does not report the number of rows before the results are read.
+func (r *QueryResult) RowCount() int64 {
+	return r.Rows.Count()
+}
+
+func (s *wrapperAthena) RunQuery(ctx context.Context, query string, opts *RunOptions) (*QueryResult, error) {
+	if opts == nil {
+		opts = &RunOptions{}
+	}
+	started, err := s.ExecuteQuery(query, opts.OutputLocation, opts.Execution...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Start query execution failure")
+	}
+	return s.awaitQueryResult(ctx, started, opts)
+}
+
+// awaitQueryResult waits for the started query and returns the handle of its results
+func (s *wrapperAthena) awaitQueryResult(
+	ctx context.Context, started *athena.StartQueryExecutionOutput, opts *RunOptions) (*QueryResult, error) {
+
+	jobID := aws.StringValue(started.QueryExecutionId)
+	job := s.trackedExecution(jobID)
+	execution, err := s.waitExecution(ctx, jobID, opts.Wait)
+	if err != nil {
+		return nil, err
+	}
+	return s.newQueryResult(ctx, jobID, execution, job.pricePerTB, opts.MaxResults), nil
+}
+
+func (s *wrapperAthena) newQueryResult(ctx context.Context,
+	jobID string, execution *athena.QueryExecution, pricePerTB float64, maxResults int) *QueryResult {
+
+	stats := newQueryStats(jobID, execution, pricePerTB)
+	result := &QueryResult{
+		JobID:            jobID,
+		DataScannedBytes: stats.DataScannedBytes,
+		ExecutionTime:    stats.EngineExecutionTime,
+		Stats:            stats,
+		Rows:             s.Rows(ctx, jobID, maxResults),
+	}
+	if execution.ResultConfiguration != nil {
+		result.OutputLocation = aws.StringValue(execution.ResultConfiguration.OutputLocation)
+	}
+	return result
+}
@@ -0,0 +1,82 @@
+package athena
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+)
+
+func makeFinishedExecutionCh(state string, reason string) chan *athena.GetQueryExecutionOutput {
+	outputCh := make(chan *athena.GetQueryExecutionOutput, 2)
+	outputCh <- <-makeStateCh("RUNNING")
+	outputCh <- &athena.GetQueryExecutionOutput{
+		QueryExecution: &athena.QueryExecution{
+			Query:            aws.String("dummyQuery"),
+			QueryExecutionId: aws.String("dummyQueryID"),
+			ResultConfiguration: &athena.ResultConfiguration{
+				OutputLocation: aws.String("s3://test-bucket/dummyQueryID.csv"),
+			},
+			Statistics: &athena.QueryExecutionStatistics{
+				DataScannedInBytes:          aws.Int64(1024),
+				EngineExecutionTimeInMillis: aws.Int64(1500),
+			},
+			Status: &athena.QueryExecutionStatus{
+				State:             aws.String(state),
+				StateChangeReason: aws.String(reason),
+			},
+		},
+	}
+	return outputCh
+}
+
+func TestRunQuery(t *testing.T) {
+	athenaMock := &AthenaMock{
+		GetQueryExecutionOutputCh: makeFinishedExecutionCh("SUCCEEDED", ""),
+		ResultPages:               makeResultPages(makeColumns("id", "bigint"), []string{"1", "2"}),
+	}
+	athenaWrapper := New(athenaMock, "test-db")
+
+	result, err := athenaWrapper.RunQuery(context.Background(), "dummyQuery", &RunOptions{
+		OutputLocation: "s3://test-bucket",
+		Wait:           &WaitOptions{InitialInterval: time.Millisecond},
+	})
+	if err != nil {
+		t.Fatalf("Run query failure %s", err.Error())
+	}
+	defer result.Rows.Close()
+	if result.JobID != "dummyQueryID" {
+		t.Fatalf("Wrong job id %s", result.JobID)
+	}
+	if result.OutputLocation != "s3://test-bucket/dummyQueryID.csv" {
+		t.Fatalf("Wrong output location %s", result.OutputLocation)
+	}
+	if result.DataScannedBytes != 1024 || result.ExecutionTime != 1500*time.Millisecond {
+		t.Fatalf("Wrong statistics %+v", result)
+	}
+	for result.Rows.Next() {
+	}
+	if result.RowCount() != 2 {
+		t.Fatalf("Wrong row count %d", result.RowCount())
+	}
+}
+
+func TestRunQueryFailed(t *testing.T) {
+	athenaMock := &AthenaMock{
+		GetQueryExecutionOutputCh: makeFinishedExecutionCh("FAILED", "SYNTAX_ERROR: line 1:8"),
+	}
+	athenaWrapper := New(athenaMock, "test-db")
+
+	_, err := athenaWrapper.RunQuery(context.Background(), "dummyQuery", &RunOptions{
+		Wait: &WaitOptions{InitialInterval: time.Millisecond},
+	})
+	failed, ok := err.(*QueryFailedError)
+	if !ok {
+		t.Fatalf("Wrong error %v", err)
+	}
+	if failed.StateChangeReason != "SYNTAX_ERROR: line 1:8" {
+		t.Fatalf("Wrong reason %s", failed.StateChangeReason)
+	}
+}
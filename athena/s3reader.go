@@ -0,0 +1,388 @@
+package athena
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultPartSize        = 8 << 20
+	defaultPartConcurrency = 4
+)
+
+// AWSS3 is the s3 client which S3Rows reads the result objects with
+type AWSS3 interface {
+	GetObject(input *s3.GetObjectInput) (*s3.GetObjectOutput, error)
+	HeadObject(input *s3.HeadObjectInput) (*s3.HeadObjectOutput, error)
+}
+
+// S3ReadOptions is options of reading query results from the output location
+type S3ReadOptions struct {
+	// PartSize is the byte size of a ranged download. Results larger than PartSize are downloaded
+	// in parts concurrently and reassembled in order. Default is 8MB.
+	PartSize int64
+	// Concurrency is the number of parts downloaded at once. Default is 4.
+	Concurrency int
+	// PageSize is the number of rows passed to the iterator at once. Default is 1000.
+	PageSize int
+}
+
+func (o *S3ReadOptions) withDefaults() S3ReadOptions {
+	opts := S3ReadOptions{}
+	if o != nil {
+		opts = *o
+	}
+	if opts.PartSize <= 0 {
+		opts.PartSize = defaultPartSize
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultPartConcurrency
+	}
+	if opts.PageSize <= 0 {
+		opts.PageSize = maxResultsPerPage
+	}
+	return opts
+}
+
+// S3Rows returns the iterator over the results of the succeeded query read from the CSV object
+// in the output location, which is much faster than GetQueryResults for large results.
+// The column types are taken from the metadata of GetQueryResults. Results which are not CSV,
+// like the outputs of DDL, are read by GetQueryResults as Rows does.
+func (s *wrapperAthena) S3Rows(ctx context.Context, jobID string, client AWSS3, opts *S3ReadOptions) *RowIterator {
+	return newRowIterator(ctx, &s3PageSource{
+		wrapper: s,
+		client:  client,
+		jobID:   jobID,
+		opts:    opts.withDefaults(),
+	})
+}
+
+// s3PageSource makes pages from the CSV result object
+type s3PageSource struct {
+	wrapper  *wrapperAthena
+	client   AWSS3
+	jobID    string
+	opts     S3ReadOptions
+	columns  []*athena.ColumnInfo
+	body     io.ReadCloser
+	reader   *csvReader
+	fallback pageSource
+}
+
+func (p *s3PageSource) nextPage(ctx context.Context) (*athena.GetQueryResultsOutput, bool, error) {
+	if p.reader == nil && p.fallback == nil {
+		if err := p.open(ctx); err != nil {
+			return nil, false, err
+		}
+	}
+	if p.fallback != nil {
+		return p.fallback.nextPage(ctx)
+	}
+
+	rows := make([]*athena.Row, 0, p.opts.PageSize)
+	last := false
+	for len(rows) < p.opts.PageSize {
+		record, err := p.reader.read()
+		if err == io.EOF {
+			last = true
+			break
+		}
+		if err != nil {
+			return nil, false, errors.Wrap(err, fmt.Sprintf("Read result object failure jobId:%s", p.jobID))
+		}
+		row := &athena.Row{Data: make([]*athena.Datum, len(record))}
+		for i, value := range record {
+			row.Data[i] = &athena.Datum{VarCharValue: value}
+		}
+		rows = append(rows, row)
+	}
+	return &athena.GetQueryResultsOutput{
+		ResultSet: &athena.ResultSet{
+			ResultSetMetadata: &athena.ResultSetMetadata{ColumnInfo: p.columns},
+			Rows:              rows,
+		},
+	}, last, nil
+}
+
+// open finds the result object of the execution and starts downloading it
+func (p *s3PageSource) open(ctx context.Context) error {
+	status, err := p.wrapper.CheckJobStatus(p.jobID)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("Check job status failure jobId:%s", p.jobID))
+	}
+	execution := status.QueryExecution
+	if execution == nil || execution.Status == nil ||
+		aws.StringValue(execution.Status.State) != athena.QueryExecutionStateSucceeded {
+		return errors.New(fmt.Sprintf("Job is not succeeded jobId:%s", p.jobID))
+	}
+	location := ""
+	if execution.ResultConfiguration != nil {
+		location = aws.StringValue(execution.ResultConfiguration.OutputLocation)
+	}
+	if !strings.HasSuffix(location, ".csv") {
+		p.fallback = &queryPageSource{client: p.wrapper.Client, jobID: p.jobID, maxResults: maxResultsPerPage}
+		return nil
+	}
+	bucket, key, err := parseS3Location(location)
+	if err != nil {
+		return err
+	}
+
+	metadata, err := p.wrapper.GetResults(p.jobID, "", 1)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("Get result metadata failure jobId:%s", p.jobID))
+	}
+	if metadata.ResultSet != nil && metadata.ResultSet.ResultSetMetadata != nil {
+		p.columns = metadata.ResultSet.ResultSetMetadata.ColumnInfo
+	}
+
+	head, err := p.client.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("Head result object failure location=%s", location))
+	}
+	size := aws.Int64Value(head.ContentLength)
+	if size <= p.opts.PartSize {
+		output, err := p.client.GetObject(&s3.GetObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		})
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("Get result object failure location=%s", location))
+		}
+		p.body = output.Body
+	} else {
+		p.body = newRangeReader(ctx, p.client, &s3.GetObjectInput{
+			Bucket:  aws.String(bucket),
+			Key:     aws.String(key),
+			IfMatch: head.ETag,
+		}, size, p.opts)
+	}
+	p.reader = newCSVReader(p.body)
+	return nil
+}
+
+func (p *s3PageSource) close() error {
+	if p.body != nil {
+		return p.body.Close()
+	}
+	return nil
+}
+
+// parseS3Location splits s3://bucket/key into the bucket and the key
+func parseS3Location(location string) (string, string, error) {
+	path := strings.TrimPrefix(location, "s3://")
+	parts := strings.SplitN(path, "/", 2)
+	if path == location || len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", errors.New(fmt.Sprintf("Invalid s3 location location=%s", location))
+	}
+	return parts[0], parts[1], nil
+}
+
+// partResult is the downloaded bytes of a part
+type partResult struct {
+	data []byte
+	err  error
+}
+
+// rangeReader downloads the parts of an object concurrently and reads them in order.
+// At most Concurrency parts wait to be read, so the memory is bounded by Concurrency * PartSize.
+type rangeReader struct {
+	cancel  context.CancelFunc
+	parts   chan chan partResult
+	current []byte
+	err     error
+}
+
+func newRangeReader(ctx context.Context, client AWSS3, input *s3.GetObjectInput, size int64, opts S3ReadOptions) *rangeReader {
+	ctx, cancel := context.WithCancel(ctx)
+	r := &rangeReader{
+		cancel: cancel,
+		parts:  make(chan chan partResult, opts.Concurrency-1),
+	}
+	go func() {
+		defer close(r.parts)
+		for start := int64(0); start < size; start += opts.PartSize {
+			end := start + opts.PartSize
+			if end > size {
+				end = size
+			}
+			result := make(chan partResult, 1)
+			select {
+			case r.parts <- result:
+			case <-ctx.Done():
+				return
+			}
+			go func(start int64, end int64) {
+				result <- downloadPart(ctx, client, input, start, end)
+			}(start, end)
+		}
+	}()
+	return r
+}
+
+func downloadPart(ctx context.Context, client AWSS3, input *s3.GetObjectInput, start int64, end int64) partResult {
+	if err := ctx.Err(); err != nil {
+		return partResult{err: err}
+	}
+	partInput := *input
+	partInput.Range = aws.String(fmt.Sprintf("bytes=%d-%d", start, end-1))
+	output, err := client.GetObject(&partInput)
+	if err != nil {
+		return partResult{err: errors.Wrap(err, fmt.Sprintf("Get object part failure range=%s", *partInput.Range))}
+	}
+	defer output.Body.Close()
+	data, err := ioutil.ReadAll(output.Body)
+	if err != nil {
+		return partResult{err: errors.Wrap(err, fmt.Sprintf("Read object part failure range=%s", *partInput.Range))}
+	}
+	if int64(len(data)) != end-start {
+		return partResult{err: errors.New(fmt.Sprintf(
+			"Short object part range=%s size=%d", *partInput.Range, len(data)))}
+	}
+	return partResult{data: data}
+}
+
+func (r *rangeReader) Read(b []byte) (int, error) {
+	for len(r.current) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		part, ok := <-r.parts
+		if !ok {
+			r.err = io.EOF
+			return 0, r.err
+		}
+		result := <-part
+		if result.err != nil {
+			r.err = result.err
+			r.cancel()
+			return 0, r.err
+		}
+		r.current = result.data
+	}
+	n := copy(b, r.current)
+	r.current = r.current[n:]
+	return n, nil
+}
+
+func (r *rangeReader) Close() error {
+	r.cancel()
+	r.current = nil
+	if r.err == nil {
+		r.err = errors.New("Read on closed reader")
+	}
+	return nil
+}
+
+// csvReader reads the records of athena CSV results. Unlike encoding/csv, it keeps NULL,
+// which athena writes as an unquoted empty field, apart from the empty string written as "".
+type csvReader struct {
+	r *bufio.Reader
+}
+
+func newCSVReader(r io.Reader) *csvReader {
+	return &csvReader{r: bufio.NewReader(r)}
+}
+
+// read returns the fields of the next record, where nil is NULL
+func (r *csvReader) read() ([]*string, error) {
+	var record []*string
+	for {
+		value, delim, err := r.readField()
+		if err == io.EOF {
+			if record == nil {
+				return nil, io.EOF
+			}
+			return append(record, nil), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		record = append(record, value)
+		if delim != ',' {
+			return record, nil
+		}
+	}
+}
+
+// readField returns the value of the next field and the delimiter after it, which is ',', '\n' or 0 at EOF
+func (r *csvReader) readField() (*string, byte, error) {
+	c, err := r.r.ReadByte()
+	if err != nil {
+		return nil, 0, err
+	}
+	if c != '"' {
+		var b strings.Builder
+		for {
+			switch c {
+			case ',':
+				return nullable(b.String()), ',', nil
+			case '\n':
+				return nullable(strings.TrimSuffix(b.String(), "\r")), '\n', nil
+			}
+			b.WriteByte(c)
+			if c, err = r.r.ReadByte(); err == io.EOF {
+				return nullable(strings.TrimSuffix(b.String(), "\r")), 0, nil
+			} else if err != nil {
+				return nil, 0, err
+			}
+		}
+	}
+
+	var b strings.Builder
+	for {
+		c, err := r.r.ReadByte()
+		if err == io.EOF {
+			return nil, 0, errors.New("Unterminated quoted field")
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		if c != '"' {
+			b.WriteByte(c)
+			continue
+		}
+		next, err := r.r.ReadByte()
+		if err == io.EOF {
+			value := b.String()
+			return &value, 0, nil
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		switch next {
+		case '"':
+			b.WriteByte('"')
+			continue
+		case '\r':
+			if following, err := r.r.ReadByte(); err == nil && following != '\n' {
+				r.r.UnreadByte()
+			}
+			next = '\n'
+		case ',', '\n':
+		default:
+			return nil, 0, errors.New(fmt.Sprintf("Unexpected character after quoted field char=%q", next))
+		}
+		value := b.String()
+		return &value, next, nil
+	}
+}
+
+func nullable(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,200 @@
+package athena
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/athena"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+type S3Mock struct {
+	mu      sync.Mutex
+	Objects map[string][]byte
+	Ranges  []string
+	IfMatch []string
+}
+
+func (s *S3Mock) GetObject(input *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+	data, ok := s.Objects[aws.StringValue(input.Bucket)+"/"+aws.StringValue(input.Key)]
+	if !ok {
+		return nil, awserr.New(s3.ErrCodeNoSuchKey, "dummy not found", nil)
+	}
+	s.mu.Lock()
+	s.Ranges = append(s.Ranges, aws.StringValue(input.Range))
+	s.IfMatch = append(s.IfMatch, aws.StringValue(input.IfMatch))
+	s.mu.Unlock()
+	if input.Range != nil {
+		var start, end int
+		fmt.Sscanf(aws.StringValue(input.Range), "bytes=%d-%d", &start, &end)
+		data = data[start : end+1]
+	}
+	return &s3.GetObjectOutput{Body: ioutil.NopCloser(bytes.NewReader(data))}, nil
+}
+
+func (s *S3Mock) HeadObject(input *s3.HeadObjectInput) (*s3.HeadObjectOutput, error) {
+	data, ok := s.Objects[aws.StringValue(input.Bucket)+"/"+aws.StringValue(input.Key)]
+	if !ok {
+		return nil, awserr.New("NotFound", "dummy not found", nil)
+	}
+	return &s3.HeadObjectOutput{
+		ContentLength: aws.Int64(int64(len(data))),
+		ETag:          aws.String("dummyETag"),
+	}, nil
+}
+
+func makeSucceededCh(location string) chan *athena.GetQueryExecutionOutput {
+	outputCh := makeFinishedExecutionCh("SUCCEEDED", "")
+	<-outputCh
+	output := <-outputCh
+	output.QueryExecution.ResultConfiguration.OutputLocation = aws.String(location)
+	outputCh <- output
+	return outputCh
+}
+
+type s3Record struct {
+	ID   int64   `athena:"id"`
+	Name *string `athena:"name"`
+}
+
+func TestS3Rows(t *testing.T) {
+	athenaMock := &AthenaMock{
+		GetQueryExecutionOutputCh: makeSucceededCh("s3://test-bucket/results/dummyQueryID.csv"),
+		ResultPages:               makeResultPages(makeColumns("id", "bigint", "name", "varchar"), []string{}),
+	}
+	s3Mock := &S3Mock{Objects: map[string][]byte{
+		"test-bucket/results/dummyQueryID.csv": []byte(
+			"\"id\",\"name\"\n\"1\",\"a,b\"\n\"2\",\n\"3\",\"\"\n\"4\",\"line\nbreak \"\"quoted\"\"\"\n"),
+	}}
+	athenaWrapper := New(athenaMock, "test-db")
+
+	it := athenaWrapper.S3Rows(context.Background(), "dummyQueryID", s3Mock, nil)
+	defer it.Close()
+	records := []s3Record{}
+	for it.Next() {
+		record := s3Record{}
+		if err := it.Scan(&record); err != nil {
+			t.Fatalf("Failed to scan row %v", err)
+		}
+		records = append(records, record)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Failed to iterate rows %v", err)
+	}
+	if len(records) != 4 {
+		t.Fatalf("Invalid number of rows %d", len(records))
+	}
+	if records[0].ID != 1 || aws.StringValue(records[0].Name) != "a,b" {
+		t.Fatalf("Invalid first row %+v", records[0])
+	}
+	if records[1].Name != nil {
+		t.Fatalf("Unquoted empty field must be NULL name=%s", *records[1].Name)
+	}
+	if records[2].Name == nil || *records[2].Name != "" {
+		t.Fatalf("Quoted empty field must be empty string %+v", records[2])
+	}
+	if aws.StringValue(records[3].Name) != "line\nbreak \"quoted\"" {
+		t.Fatalf("Invalid quoted field %q", aws.StringValue(records[3].Name))
+	}
+	if len(s3Mock.Ranges) != 1 || s3Mock.Ranges[0] != "" {
+		t.Fatalf("Small object must be downloaded at once ranges=%v", s3Mock.Ranges)
+	}
+}
+
+func TestS3RowsRanged(t *testing.T) {
+	lines := []string{"\"id\""}
+	for i := 1; i <= 100; i++ {
+		lines = append(lines, fmt.Sprintf("\"%d\"", i))
+	}
+	athenaMock := &AthenaMock{
+		GetQueryExecutionOutputCh: makeSucceededCh("s3://test-bucket/dummyQueryID.csv"),
+		ResultPages:               makeResultPages(makeColumns("id", "bigint"), []string{}),
+	}
+	s3Mock := &S3Mock{Objects: map[string][]byte{
+		"test-bucket/dummyQueryID.csv": []byte(strings.Join(lines, "\n") + "\n"),
+	}}
+	athenaWrapper := New(athenaMock, "test-db")
+
+	it := athenaWrapper.S3Rows(context.Background(), "dummyQueryID", s3Mock, &S3ReadOptions{
+		PartSize:    16,
+		Concurrency: 3,
+		PageSize:    7,
+	})
+	defer it.Close()
+	expected := int64(1)
+	for it.Next() {
+		record := s3Record{}
+		if err := it.Scan(&record); err != nil {
+			t.Fatalf("Failed to scan row %v", err)
+		}
+		if record.ID != expected {
+			t.Fatalf("Rows must keep the order expected=%d actual=%d", expected, record.ID)
+		}
+		expected++
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Failed to iterate rows %v", err)
+	}
+	if it.Count() != 100 {
+		t.Fatalf("Invalid number of rows %d", it.Count())
+	}
+	if len(s3Mock.Ranges) < 2 {
+		t.Fatalf("Large object must be downloaded in parts ranges=%v", s3Mock.Ranges)
+	}
+	for i, etag := range s3Mock.IfMatch {
+		if etag != "dummyETag" || s3Mock.Ranges[i] == "" {
+			t.Fatalf("Parts must be ranged downloads of the same object range=%s etag=%s", s3Mock.Ranges[i], etag)
+		}
+	}
+}
+
+func TestS3RowsFallback(t *testing.T) {
+	athenaMock := &AthenaMock{
+		GetQueryExecutionOutputCh: makeSucceededCh("s3://test-bucket/dummyQueryID.txt"),
+		ResultPages:               makeResultPages(makeColumns("partition", "string"), []string{"dt=2020-01-01"}),
+	}
+	s3Mock := &S3Mock{}
+	athenaWrapper := New(athenaMock, "test-db")
+
+	it := athenaWrapper.S3Rows(context.Background(), "dummyQueryID", s3Mock, nil)
+	defer it.Close()
+	if !it.Next() || aws.StringValue(it.Row().Data[0].VarCharValue) != "dt=2020-01-01" {
+		t.Fatalf("Non CSV results must be read by GetQueryResults err=%v", it.Err())
+	}
+	if len(s3Mock.Ranges) != 0 {
+		t.Fatalf("Non CSV results must not be downloaded")
+	}
+}
+
+func TestS3RowsMissingObject(t *testing.T) {
+	athenaMock := &AthenaMock{
+		GetQueryExecutionOutputCh: makeSucceededCh("s3://test-bucket/dummyQueryID.csv"),
+		ResultPages:               makeResultPages(makeColumns("id", "bigint"), []string{}),
+	}
+	athenaWrapper := New(athenaMock, "test-db")
+
+	it := athenaWrapper.S3Rows(context.Background(), "dummyQueryID", &S3Mock{}, nil)
+	defer it.Close()
+	if it.Next() || it.Err() == nil {
+		t.Fatalf("Missing result object must be error")
+	}
+}
+
+func TestParseS3Location(t *testing.T) {
+	bucket, key, err := parseS3Location("s3://test-bucket/path/to/result.csv")
+	if err != nil || bucket != "test-bucket" || key != "path/to/result.csv" {
+		t.Fatalf("Invalid location bucket=%s key=%s err=%v", bucket, key, err)
+	}
+	for _, location := range []string{"test-bucket/key", "s3://test-bucket", "s3:///key"} {
+		if _, _, err := parseS3Location(location); err == nil {
+			t.Fatalf("Invalid location must be rejected location=%s", location)
+		}
+	}
+}
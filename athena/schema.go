@@ -0,0 +1,243 @@
+package athena
+
+import (
+	"context"
+	"fmt"
+	"go/format"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+	"github.com/pkg/errors"
+)
+
+const defaultCatalog = "AwsDataCatalog"
+
+// commonInitialisms are the words written in upper case in generated field names
+var commonInitialisms = map[string]bool{
+	"api": true, "arn": true, "html": true, "http": true, "id": true, "ip": true,
+	"json": true, "sql": true, "uri": true, "url": true, "uuid": true, "xml": true,
+}
+
+// ColumnDescriptor describes a column of a table in the data catalog
+type ColumnDescriptor struct {
+	Name string
+	// Type is the hive type of the column like bigint, varchar(10) or array<string>
+	Type string
+	// PartitionKey reports whether the column is a partition key
+	PartitionKey bool
+	Comment      string
+}
+
+// TableSchema is the definition of a table in the data catalog
+type TableSchema struct {
+	Database  string
+	Name      string
+	TableType string
+	// Columns is the columns of the table followed by the partition keys
+	Columns []ColumnDescriptor
+}
+
+// catalogOrDefault returns the data catalog of the config, or the default catalog of athena
+func (c executionConfig) catalogOrDefault() string {
+	if c.catalog == "" {
+		return defaultCatalog
+	}
+	return c.catalog
+}
+
+func (s *wrapperAthena) ListDatabases(ctx context.Context, opts ...Option) ([]string, error) {
+	input := &athena.ListDatabasesInput{
+		CatalogName: aws.String(s.executionConfig(opts).catalogOrDefault()),
+	}
+	databases := []string{}
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		page, err := s.Client.ListDatabases(input)
+		if err != nil {
+			return nil, errors.Wrap(err, "List databases failure")
+		}
+		for _, database := range page.DatabaseList {
+			databases = append(databases, aws.StringValue(database.Name))
+		}
+		if aws.StringValue(page.NextToken) == "" {
+			return databases, nil
+		}
+		input.NextToken = page.NextToken
+	}
+}
+
+// ListTables returns the table names in the database. Empty database means the database of the client.
+func (s *wrapperAthena) ListTables(ctx context.Context, database string, opts ...Option) ([]string, error) {
+	conf := s.executionConfig(opts)
+	if database == "" {
+		database = conf.database
+	}
+	input := &athena.ListTableMetadataInput{
+		CatalogName:  aws.String(conf.catalogOrDefault()),
+		DatabaseName: aws.String(database),
+	}
+	tables := []string{}
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		page, err := s.Client.ListTableMetadata(input)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("List tables failure database=%s", database))
+		}
+		for _, table := range page.TableMetadataList {
+			tables = append(tables, aws.StringValue(table.Name))
+		}
+		if aws.StringValue(page.NextToken) == "" {
+			return tables, nil
+		}
+		input.NextToken = page.NextToken
+	}
+}
+
+// GetTableSchema returns the columns of the table. Empty database means the database of the client.
+func (s *wrapperAthena) GetTableSchema(database string, table string, opts ...Option) (*TableSchema, error) {
+	conf := s.executionConfig(opts)
+	if database == "" {
+		database = conf.database
+	}
+	output, err := s.Client.GetTableMetadata(&athena.GetTableMetadataInput{
+		CatalogName:  aws.String(conf.catalogOrDefault()),
+		DatabaseName: aws.String(database),
+		TableName:    aws.String(table),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("Get table metadata failure database=%s table=%s", database, table))
+	}
+	metadata := output.TableMetadata
+	schema := &TableSchema{
+		Database:  database,
+		Name:      aws.StringValue(metadata.Name),
+		TableType: aws.StringValue(metadata.TableType),
+		Columns:   make([]ColumnDescriptor, 0, len(metadata.Columns)+len(metadata.PartitionKeys)),
+	}
+	for _, column := range metadata.Columns {
+		schema.Columns = append(schema.Columns, newColumnDescriptor(column, false))
+	}
+	for _, column := range metadata.PartitionKeys {
+		schema.Columns = append(schema.Columns, newColumnDescriptor(column, true))
+	}
+	return schema, nil
+}
+
+func newColumnDescriptor(column *athena.Column, partitionKey bool) ColumnDescriptor {
+	return ColumnDescriptor{
+		Name:         aws.StringValue(column.Name),
+		Type:         aws.StringValue(column.Type),
+		PartitionKey: partitionKey,
+		Comment:      aws.StringValue(column.Comment),
+	}
+}
+
+// GoStruct returns the formatted go struct definition whose fields are tagged with the column names,
+// so that ResultDecoder and RowIterator.Scan decode the rows of the table into it.
+// Nullable makes the scalar fields pointers to keep NULL apart from the zero value.
+func (t *TableSchema) GoStruct(name string, nullable bool) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "// %s is a row of %s.%s\n", name, t.Database, t.Name)
+	fmt.Fprintf(&b, "type %s struct {\n", name)
+	used := map[string]int{}
+	for _, column := range t.Columns {
+		fieldName := goFieldName(column.Name)
+		used[fieldName]++
+		if n := used[fieldName]; n > 1 {
+			fieldName = fmt.Sprintf("%s%d", fieldName, n)
+		}
+		fieldType := goType(column.Type)
+		if nullable && !strings.HasPrefix(fieldType, "[]") && !strings.HasPrefix(fieldType, "map[") {
+			fieldType = "*" + fieldType
+		}
+		fmt.Fprintf(&b, "%s %s `%s:%q`", fieldName, fieldType, tagName, column.Name)
+		if column.Comment != "" {
+			fmt.Fprintf(&b, " // %s", strings.Replace(column.Comment, "\n", " ", -1))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("}\n")
+
+	source, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return "", errors.Wrap(err, fmt.Sprintf("Format struct failure name=%s", name))
+	}
+	return string(source), nil
+}
+
+// goType returns the go type which the decoder sets the values of the hive type into
+func goType(columnType string) string {
+	columnType = strings.ToLower(strings.TrimSpace(columnType))
+	if strings.HasPrefix(columnType, "array<") && strings.HasSuffix(columnType, ">") {
+		return "[]" + goElementType(columnType[len("array<"):len(columnType)-1])
+	}
+	if strings.HasPrefix(columnType, "map<") && strings.HasSuffix(columnType, ">") {
+		depth := 0
+		for i, c := range columnType[len("map<") : len(columnType)-1] {
+			switch {
+			case c == '<' || c == '(':
+				depth++
+			case c == '>' || c == ')':
+				depth--
+			case c == ',' && depth == 0:
+				return "map[string]" + goElementType(columnType[len("map<")+i+1:len(columnType)-1])
+			}
+		}
+		return "map[string]string"
+	}
+	switch baseType(columnType) {
+	case "tinyint":
+		return "int8"
+	case "smallint":
+		return "int16"
+	case "int", "integer":
+		return "int32"
+	case "bigint":
+		return "int64"
+	case "float", "real":
+		return "float32"
+	case "double":
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "date", "timestamp":
+		return "time.Time"
+	}
+	return "string"
+}
+
+// goElementType returns the go type of the elements of arrays and maps, which are decoded from the text
+func goElementType(columnType string) string {
+	elementType := goType(columnType)
+	switch elementType {
+	case "int8", "int16", "int32", "int64", "float32", "float64", "bool":
+		return elementType
+	}
+	return "string"
+}
+
+// goFieldName returns the exported go identifier of the column name like user_id to UserID
+func goFieldName(column string) string {
+	words := strings.FieldsFunc(column, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	})
+	var b strings.Builder
+	for _, word := range words {
+		lower := strings.ToLower(word)
+		if commonInitialisms[lower] {
+			b.WriteString(strings.ToUpper(lower))
+			continue
+		}
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	name := b.String()
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		name = "Column" + name
+	}
+	return name
+}
@@ -0,0 +1,147 @@
+package athena
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+)
+
+func makeTables() map[string][]*athena.TableMetadata {
+	return map[string][]*athena.TableMetadata{
+		"test-db": {
+			{
+				Name:      aws.String("events"),
+				TableType: aws.String("EXTERNAL_TABLE"),
+				Columns: []*athena.Column{
+					{Name: aws.String("event_id"), Type: aws.String("bigint"), Comment: aws.String("id of the event")},
+					{Name: aws.String("user_name"), Type: aws.String("varchar(64)")},
+					{Name: aws.String("score"), Type: aws.String("double")},
+					{Name: aws.String("created_at"), Type: aws.String("timestamp")},
+					{Name: aws.String("tags"), Type: aws.String("array<string>")},
+					{Name: aws.String("counts"), Type: aws.String("map<string,int>")},
+					{Name: aws.String("price"), Type: aws.String("decimal(10,2)")},
+				},
+				PartitionKeys: []*athena.Column{
+					{Name: aws.String("dt"), Type: aws.String("string")},
+				},
+			},
+			{Name: aws.String("users"), TableType: aws.String("EXTERNAL_TABLE")},
+		},
+		"other-db": {},
+	}
+}
+
+func TestListDatabases(t *testing.T) {
+	athenaMock := &AthenaMock{Tables: makeTables()}
+	athenaWrapper := New(athenaMock, "test-db")
+
+	databases, err := athenaWrapper.ListDatabases(context.Background())
+	if err != nil {
+		t.Fatalf("List databases failure %v", err)
+	}
+	if strings.Join(databases, ",") != "other-db,test-db" {
+		t.Fatalf("Invalid databases %v", databases)
+	}
+	if athenaMock.CatalogName != "AwsDataCatalog" {
+		t.Fatalf("Default catalog must be used catalog=%s", athenaMock.CatalogName)
+	}
+
+	if _, err := athenaWrapper.ListDatabases(context.Background(), WithCatalog("other")); err != nil {
+		t.Fatalf("List databases failure %v", err)
+	}
+	if athenaMock.CatalogName != "other" {
+		t.Fatalf("Catalog option must be used catalog=%s", athenaMock.CatalogName)
+	}
+}
+
+func TestListTables(t *testing.T) {
+	athenaMock := &AthenaMock{Tables: makeTables()}
+	athenaWrapper := New(athenaMock, "test-db")
+
+	tables, err := athenaWrapper.ListTables(context.Background(), "")
+	if err != nil {
+		t.Fatalf("List tables failure %v", err)
+	}
+	if strings.Join(tables, ",") != "events,users" {
+		t.Fatalf("Invalid tables %v", tables)
+	}
+	if _, err := athenaWrapper.ListTables(context.Background(), "missing-db"); err == nil {
+		t.Fatalf("Missing database must be error")
+	}
+}
+
+func TestGetTableSchema(t *testing.T) {
+	athenaMock := &AthenaMock{Tables: makeTables()}
+	athenaWrapper := New(athenaMock, "test-db")
+
+	schema, err := athenaWrapper.GetTableSchema("", "events")
+	if err != nil {
+		t.Fatalf("Get table schema failure %v", err)
+	}
+	if schema.Database != "test-db" || schema.Name != "events" || len(schema.Columns) != 8 {
+		t.Fatalf("Invalid schema %+v", schema)
+	}
+	if schema.Columns[0].Comment != "id of the event" || schema.Columns[0].PartitionKey {
+		t.Fatalf("Invalid column %+v", schema.Columns[0])
+	}
+	if last := schema.Columns[7]; last.Name != "dt" || !last.PartitionKey {
+		t.Fatalf("Partition keys must follow the columns %+v", last)
+	}
+	if _, err := athenaWrapper.GetTableSchema("", "missing"); err == nil {
+		t.Fatalf("Missing table must be error")
+	}
+}
+
+func TestGoStruct(t *testing.T) {
+	athenaMock := &AthenaMock{Tables: makeTables()}
+	athenaWrapper := New(athenaMock, "test-db")
+	schema, err := athenaWrapper.GetTableSchema("", "events")
+	if err != nil {
+		t.Fatalf("Get table schema failure %v", err)
+	}
+
+	source, err := schema.GoStruct("Event", false)
+	if err != nil {
+		t.Fatalf("Generate struct failure %v", err)
+	}
+	for _, field := range []string{
+		"EventID   int64            `athena:\"event_id\"` // id of the event",
+		"UserName  string           `athena:\"user_name\"`",
+		"Score     float64          `athena:\"score\"`",
+		"CreatedAt time.Time        `athena:\"created_at\"`",
+		"Tags      []string         `athena:\"tags\"`",
+		"Counts    map[string]int32 `athena:\"counts\"`",
+		"Price     string           `athena:\"price\"`",
+		"Dt        string           `athena:\"dt\"`",
+	} {
+		if !strings.Contains(source, field) {
+			t.Fatalf("Generated struct must contain %s\n%s", field, source)
+		}
+	}
+
+	source, err = schema.GoStruct("Event", true)
+	if err != nil {
+		t.Fatalf("Generate struct failure %v", err)
+	}
+	if !strings.Contains(source, "*int64") || strings.Contains(source, "*[]string") {
+		t.Fatalf("Only scalar fields must be pointers\n%s", source)
+	}
+}
+
+func TestGoFieldName(t *testing.T) {
+	cases := map[string]string{
+		"user_id":    "UserID",
+		"ClickCount": "ClickCount",
+		"page-url":   "PageURL",
+		"1st_value":  "Column1stValue",
+		"__":         "Column",
+	}
+	for column, expected := range cases {
+		if actual := goFieldName(column); actual != expected {
+			t.Fatalf("Invalid field name column=%s expected=%s actual=%s", column, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package athena
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+)
+
+const (
+	// DefaultPricePerTB is the price in USD of scanning 1 TB data in most regions
+	DefaultPricePerTB = 5.0
+	bytesPerTB        = 1 << 40
+	// athena bills 10 MB at least for every query which scans data
+	minBilledBytes = 10 << 20
+)
+
+// QueryStats is statistics of a finished query execution
+type QueryStats struct {
+	JobID string
+	// State is the final state of the execution
+	State string
+	// StatementType is DDL, DML or UTILITY
+	StatementType         string
+	DataScannedBytes      int64
+	EngineExecutionTime   time.Duration
+	QueryQueueTime        time.Duration
+	QueryPlanningTime     time.Duration
+	ServiceProcessingTime time.Duration
+	TotalExecutionTime    time.Duration
+	// EstimatedCost is the estimated price in USD of the data scanned by the query
+	EstimatedCost float64
+}
+
+// WithPricePerTB sets the price in USD per TB scanned which estimates the cost of queries.
+// Default is DefaultPricePerTB. It takes effect as an option of New or of the call which starts the query.
+func WithPricePerTB(price float64) Option {
+	return func(c *executionConfig) {
+		c.pricePerTB = price
+	}
+}
+
+// WithStatsHook sets the function which receives the statistics of every query execution
+// the wrapper waits for, e.g. to push them to metrics. Every execution is reported once when
+// CheckJobStatus or a wait first sees it finish. It takes effect as an option of New
+// or of the call which starts the query.
+func WithStatsHook(hook func(*QueryStats)) Option {
+	return func(c *executionConfig) {
+		c.statsHook = hook
+	}
+}
+
+// newQueryStats returns the statistics of the execution
+func newQueryStats(jobID string, execution *athena.QueryExecution, pricePerTB float64) *QueryStats {
+	stats := &QueryStats{
+		JobID:         jobID,
+		StatementType: aws.StringValue(execution.StatementType),
+	}
+	if execution.Status != nil {
+		stats.State = aws.StringValue(execution.Status.State)
+	}
+	if statistics := execution.Statistics; statistics != nil {
+		stats.DataScannedBytes = aws.Int64Value(statistics.DataScannedInBytes)
+		stats.EngineExecutionTime = millis(statistics.EngineExecutionTimeInMillis)
+		stats.QueryQueueTime = millis(statistics.QueryQueueTimeInMillis)
+		stats.QueryPlanningTime = millis(statistics.QueryPlanningTimeInMillis)
+		stats.ServiceProcessingTime = millis(statistics.ServiceProcessingTimeInMillis)
+		stats.TotalExecutionTime = millis(statistics.TotalExecutionTimeInMillis)
+	}
+	stats.EstimatedCost = estimateCost(stats, pricePerTB)
+	return stats
+}
+
+// estimateCost follows the pricing of athena. DDL and failed queries are free,
+// and the queries which scanned data are billed for 10 MB at least.
+func estimateCost(stats *QueryStats, pricePerTB float64) float64 {
+	if stats.StatementType == athena.StatementTypeDdl || stats.State == athena.QueryExecutionStateFailed ||
+		stats.DataScannedBytes <= 0 {
+		return 0
+	}
+	billed := stats.DataScannedBytes
+	if billed < minBilledBytes {
+		billed = minBilledBytes
+	}
+	return float64(billed) / bytesPerTB * pricePerTB
+}
+
+func millis(value *int64) time.Duration {
+	return time.Duration(aws.Int64Value(value)) * time.Millisecond
+}
+
+// reportStats passes the statistics of the finished execution to the hook of the options it was started with
+func (s *wrapperAthena) reportStats(jobID string, execution *athena.QueryExecution, job trackedExecution) {
+	if job.statsHook != nil {
+		job.statsHook(newQueryStats(jobID, execution, job.pricePerTB))
+	}
+}
+
+func (s *wrapperAthena) WaitQueryStats(ctx context.Context, jobID string, opts *WaitOptions) (*QueryStats, error) {
+	job := s.trackedExecution(jobID)
+	execution, err := s.waitExecution(ctx, jobID, opts)
+	if execution == nil {
+		return nil, err
+	}
+	return newQueryStats(jobID, execution, job.pricePerTB), err
+}
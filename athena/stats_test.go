@@ -0,0 +1,158 @@
+package athena
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+)
+
+func TestWaitQueryStats(t *testing.T) {
+	hooked := []*QueryStats{}
+	athenaMock := &AthenaMock{
+		GetQueryExecutionOutputCh: makeFinishedExecutionCh("SUCCEEDED", ""),
+	}
+	athenaWrapper := New(athenaMock, "test-db",
+		WithPricePerTB(10),
+		WithStatsHook(func(stats *QueryStats) {
+			hooked = append(hooked, stats)
+		}),
+	)
+
+	stats, err := athenaWrapper.WaitQueryStats(context.Background(), "dummyQueryID", &WaitOptions{
+		InitialInterval: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Wait query stats failure %s", err.Error())
+	}
+	if stats.State != "SUCCEEDED" || stats.DataScannedBytes != 1024 || stats.EngineExecutionTime != 1500*time.Millisecond {
+		t.Fatalf("Wrong stats %+v", stats)
+	}
+	// 1024 bytes are billed as 10 MB
+	expectedCost := 10.0 * (10 << 20) / (1 << 40)
+	if math.Abs(stats.EstimatedCost-expectedCost) > 1e-12 {
+		t.Fatalf("Wrong estimated cost %f", stats.EstimatedCost)
+	}
+	if len(hooked) != 1 || hooked[0].JobID != "dummyQueryID" {
+		t.Fatalf("Stats hook must be called once %+v", hooked)
+	}
+}
+
+func TestWaitQueryStatsCallOptions(t *testing.T) {
+	clientHooked, hooked := 0, []*QueryStats{}
+	athenaMock := &AthenaMock{
+		GetQueryExecutionOutputCh: makeFinishedExecutionCh("SUCCEEDED", ""),
+	}
+	athenaWrapper := New(athenaMock, "test-db", WithStatsHook(func(stats *QueryStats) {
+		clientHooked++
+	}))
+	_, err := athenaWrapper.ExecuteQuery("dummyQuery", "",
+		WithPricePerTB(10),
+		WithStatsHook(func(stats *QueryStats) {
+			hooked = append(hooked, stats)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Execute failure %s", err.Error())
+	}
+
+	stats, err := athenaWrapper.WaitQueryStats(context.Background(), "dummyQueryID", &WaitOptions{
+		InitialInterval: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Wait query stats failure %s", err.Error())
+	}
+	expectedCost := 10.0 * (10 << 20) / (1 << 40)
+	if math.Abs(stats.EstimatedCost-expectedCost) > 1e-12 {
+		t.Fatalf("Per call price must be used %f", stats.EstimatedCost)
+	}
+	if len(hooked) != 1 || math.Abs(hooked[0].EstimatedCost-expectedCost) > 1e-12 {
+		t.Fatalf("Per call stats hook must be called once %+v", hooked)
+	}
+	if clientHooked != 0 {
+		t.Fatalf("Per call stats hook must replace the client hook but called %d", clientHooked)
+	}
+}
+
+func TestStatsHookReportsOnce(t *testing.T) {
+	hooked := 0
+	athenaMock := &AthenaMock{GetQueryExecutionOutputCh: makeStateCh("SUCCEEDED", "SUCCEEDED", "SUCCEEDED", "SUCCEEDED")}
+	athenaWrapper := New(athenaMock, "test-db", WithStatsHook(func(stats *QueryStats) {
+		hooked++
+	}))
+	if _, err := athenaWrapper.ExecuteQuery("dummyQuery", ""); err != nil {
+		t.Fatalf("Execute failure %s", err.Error())
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := athenaWrapper.WaitJobStatusContext(context.Background(), "dummyQueryID", &WaitOptions{
+			InitialInterval: time.Millisecond,
+		}); err != nil {
+			t.Fatalf("Wait job status failure %s", err.Error())
+		}
+	}
+	if hooked != 1 {
+		t.Fatalf("Stats hook must be called once but %d", hooked)
+	}
+
+	// the execution the wrapper did not start is also reported once
+	for i := 0; i < 2; i++ {
+		if _, err := athenaWrapper.WaitJobStatusContext(context.Background(), "otherQueryID", &WaitOptions{
+			InitialInterval: time.Millisecond,
+		}); err != nil {
+			t.Fatalf("Wait job status failure %s", err.Error())
+		}
+	}
+	if hooked != 2 {
+		t.Fatalf("Stats hook must be called once for the other execution but %d", hooked-1)
+	}
+}
+
+func TestRunQueryStats(t *testing.T) {
+	hooked := 0
+	athenaMock := &AthenaMock{
+		GetQueryExecutionOutputCh: makeFinishedExecutionCh("SUCCEEDED", ""),
+		ResultPages:               makeResultPages(makeColumns("id", "bigint"), []string{"1"}),
+	}
+	athenaWrapper := New(athenaMock, "test-db", WithStatsHook(func(stats *QueryStats) {
+		hooked++
+	}))
+
+	result, err := athenaWrapper.RunQuery(context.Background(), "dummyQuery", &RunOptions{
+		Wait: &WaitOptions{InitialInterval: time.Millisecond},
+	})
+	if err != nil {
+		t.Fatalf("Run query failure %s", err.Error())
+	}
+	if result.Stats == nil || result.Stats.TotalExecutionTime != 0 || result.Stats.DataScannedBytes != 1024 {
+		t.Fatalf("Wrong stats %+v", result.Stats)
+	}
+	if hooked != 1 {
+		t.Fatalf("Stats hook must be called once but %d", hooked)
+	}
+}
+
+func TestEstimateCost(t *testing.T) {
+	execution := &athena.QueryExecution{
+		StatementType: aws.String("DML"),
+		Status:        &athena.QueryExecutionStatus{State: aws.String("CANCELLED")},
+		Statistics:    &athena.QueryExecutionStatistics{DataScannedInBytes: aws.Int64(1 << 40)},
+	}
+	if cost := newQueryStats("dummyID", execution, DefaultPricePerTB).EstimatedCost; cost != 5 {
+		t.Fatalf("Cancelled query is billed for the scanned data but %f", cost)
+	}
+
+	execution.Status.State = aws.String("FAILED")
+	if cost := newQueryStats("dummyID", execution, DefaultPricePerTB).EstimatedCost; cost != 0 {
+		t.Fatalf("Failed query must be free but %f", cost)
+	}
+
+	execution.Status.State = aws.String("SUCCEEDED")
+	execution.StatementType = aws.String("DDL")
+	if cost := newQueryStats("dummyID", execution, DefaultPricePerTB).EstimatedCost; cost != 0 {
+		t.Fatalf("DDL must be free but %f", cost)
+	}
+}
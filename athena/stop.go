@@ -0,0 +1,154 @@
+package athena
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+	"github.com/pkg/errors"
+)
+
+// maxReportedJobs caps the ids of the finished executions which are kept to report their statistics once
+const maxReportedJobs = 1000
+
+// trackedExecution keeps the options of the started execution which report its statistics
+type trackedExecution struct {
+	pricePerTB float64
+	statsHook  func(*QueryStats)
+}
+
+func newTrackedExecution(conf executionConfig) trackedExecution {
+	return trackedExecution{pricePerTB: conf.pricePerTB, statsHook: conf.statsHook}
+}
+
+// trackExecution remembers the started execution until the wrapper sees it finish or stops it
+func (s *wrapperAthena) trackExecution(jobID string, conf executionConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.runningJobs == nil {
+		s.runningJobs = map[string]trackedExecution{}
+	}
+	s.runningJobs[jobID] = newTrackedExecution(conf)
+}
+
+// untrackExecution forgets the execution and returns its options if the wrapper tracked it
+func (s *wrapperAthena) untrackExecution(jobID string) (trackedExecution, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.runningJobs[jobID]
+	delete(s.runningJobs, jobID)
+	return job, ok
+}
+
+// trackedExecution returns the options the execution was started with, or the client options
+func (s *wrapperAthena) trackedExecution(jobID string) trackedExecution {
+	s.mu.Lock()
+	job, ok := s.runningJobs[jobID]
+	s.mu.Unlock()
+	if !ok {
+		return newTrackedExecution(s.executionConfig(nil))
+	}
+	return job
+}
+
+// finishExecution untracks the execution which reached a final state, stores it into the cache
+// and reports its statistics unless they are already reported. The executions the wrapper did not
+// start are reported by the client options.
+func (s *wrapperAthena) finishExecution(jobID string, execution *athena.QueryExecution) {
+	if !finished(execution) {
+		return
+	}
+	job, ok := s.untrackExecution(jobID)
+	if ok {
+		s.storeCache(jobID, execution)
+	} else {
+		job = newTrackedExecution(s.executionConfig(nil))
+	}
+	if s.markReported(jobID) {
+		s.reportStats(jobID, execution, job)
+	}
+}
+
+// markReported remembers that the statistics of the execution are reported, and reports whether
+// they were not reported yet. The oldest ids are forgotten beyond maxReportedJobs.
+func (s *wrapperAthena) markReported(jobID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.reportedJobs == nil {
+		s.reportedJobs = map[string]time.Time{}
+	}
+	_, reported := s.reportedJobs[jobID]
+	if !reported && len(s.reportedJobs) >= maxReportedJobs {
+		oldestJobID := ""
+		for reportedJobID, reportedAt := range s.reportedJobs {
+			if oldestJobID == "" || reportedAt.Before(s.reportedJobs[oldestJobID]) {
+				oldestJobID = reportedJobID
+			}
+		}
+		delete(s.reportedJobs, oldestJobID)
+	}
+	s.reportedJobs[jobID] = time.Now()
+	return !reported
+}
+
+// finished reports whether the execution reached a final state
+func finished(execution *athena.QueryExecution) bool {
+	if execution == nil || execution.Status == nil {
+		return false
+	}
+	switch aws.StringValue(execution.Status.State) {
+	case athena.QueryExecutionStateSucceeded, athena.QueryExecutionStateCancelled, athena.QueryExecutionStateFailed:
+		return true
+	}
+	return false
+}
+
+// RunningJobs returns the ids of the executions the wrapper started and has not seen finish
+func (s *wrapperAthena) RunningJobs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobIDs := make([]string, 0, len(s.runningJobs))
+	for jobID := range s.runningJobs {
+		jobIDs = append(jobIDs, jobID)
+	}
+	sort.Strings(jobIDs)
+	return jobIDs
+}
+
+func (s *wrapperAthena) StopQuery(jobID string) error {
+	_, err := s.Client.StopQueryExecution(&athena.StopQueryExecutionInput{
+		QueryExecutionId: aws.String(jobID),
+	})
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("Stop query failure jobId:%s", jobID))
+	}
+	s.untrackExecution(jobID)
+	s.forgetCacheKey(jobID)
+	return nil
+}
+
+// CancelAll stops every execution the wrapper started and has not seen finish.
+// The executions which turn out to be finished are untracked without stopping.
+// It tries all of them even if some fail, and returns the error listing the failed ones.
+func (s *wrapperAthena) CancelAll(ctx context.Context) error {
+	failures := []string{}
+	for _, jobID := range s.RunningJobs() {
+		if err := ctx.Err(); err != nil {
+			return errors.Wrap(err, "Cancel all queries aborted")
+		}
+		if status, err := s.CheckJobStatus(jobID); err == nil && finished(status.QueryExecution) {
+			continue
+		}
+		if err := s.StopQuery(jobID); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	if len(failures) > 0 {
+		return errors.New(fmt.Sprintf("Cancel all queries failure: %s", strings.Join(failures, "; ")))
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package athena
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/athena"
+)
+
+func TestStopQuery(t *testing.T) {
+	athenaMock := &AthenaMock{}
+	athenaWrapper := New(athenaMock, "test-db")
+
+	if _, err := athenaWrapper.ExecuteQuery("dummyQuery", "s3://test-bucket"); err != nil {
+		t.Fatalf("Execute failure %s", err.Error())
+	}
+	if jobIDs := athenaWrapper.RunningJobs(); len(jobIDs) != 1 || jobIDs[0] != "dummyQueryID" {
+		t.Fatalf("Started execution must be tracked %+v", jobIDs)
+	}
+	if err := athenaWrapper.StopQuery("dummyQueryID"); err != nil {
+		t.Fatalf("Stop query failure %s", err.Error())
+	}
+	if athenaMock.StopQueryExecutionCounter != 1 || len(athenaWrapper.RunningJobs()) != 0 {
+		t.Fatalf("Stopped execution must be untracked counter=%d", athenaMock.StopQueryExecutionCounter)
+	}
+}
+
+func TestCancelAll(t *testing.T) {
+	athenaMock := &AthenaMock{GetQueryExecutionOutputCh: makeStateCh("RUNNING", "RUNNING")}
+	athenaWrapper := New(athenaMock, "test-db")
+
+	if _, err := athenaWrapper.ExecuteQuery("dummyQuery", "s3://test-bucket"); err != nil {
+		t.Fatalf("Execute failure %s", err.Error())
+	}
+	athenaMock.StopQueryExecutionErr = awserr.New(athena.ErrCodeInternalServerException, "dummy error", nil)
+	if err := athenaWrapper.CancelAll(context.Background()); err == nil {
+		t.Fatalf("Failure of stopping must be reported")
+	}
+	if len(athenaWrapper.RunningJobs()) != 1 {
+		t.Fatalf("Execution failed to stop must stay tracked")
+	}
+
+	athenaMock.StopQueryExecutionErr = nil
+	if err := athenaWrapper.CancelAll(context.Background()); err != nil {
+		t.Fatalf("Cancel all failure %s", err.Error())
+	}
+	if len(athenaWrapper.RunningJobs()) != 0 {
+		t.Fatalf("Cancelled executions must be untracked")
+	}
+}
+
+func TestCheckJobStatusUntracksFinished(t *testing.T) {
+	hooked := 0
+	athenaMock := &AthenaMock{GetQueryExecutionOutputCh: makeStateCh("RUNNING", "SUCCEEDED")}
+	athenaWrapper := New(athenaMock, "test-db", WithStatsHook(func(stats *QueryStats) {
+		hooked++
+	}))
+	if _, err := athenaWrapper.ExecuteQuery("dummyQuery", "s3://test-bucket"); err != nil {
+		t.Fatalf("Execute failure %s", err.Error())
+	}
+
+	if _, err := athenaWrapper.CheckJobStatus("dummyQueryID"); err != nil {
+		t.Fatalf("Check job status failure %s", err.Error())
+	}
+	if len(athenaWrapper.RunningJobs()) != 1 {
+		t.Fatalf("Running execution must stay tracked")
+	}
+	if _, err := athenaWrapper.CheckJobStatus("dummyQueryID"); err != nil {
+		t.Fatalf("Check job status failure %s", err.Error())
+	}
+	if len(athenaWrapper.RunningJobs()) != 0 {
+		t.Fatalf("Finished execution must be untracked")
+	}
+	if hooked != 1 {
+		t.Fatalf("Stats hook must be called once but %d", hooked)
+	}
+}
+
+func TestCancelAllSkipsFinished(t *testing.T) {
+	athenaMock := &AthenaMock{GetQueryExecutionOutputCh: makeStateCh("SUCCEEDED")}
+	athenaWrapper := New(athenaMock, "test-db")
+	if _, err := athenaWrapper.ExecuteQuery("dummyQuery", "s3://test-bucket"); err != nil {
+		t.Fatalf("Execute failure %s", err.Error())
+	}
+
+	if err := athenaWrapper.CancelAll(context.Background()); err != nil {
+		t.Fatalf("Cancel all failure %s", err.Error())
+	}
+	if athenaMock.StopQueryExecutionCounter != 0 {
+		t.Fatalf("Finished execution must not be stopped counter=%d", athenaMock.StopQueryExecutionCounter)
+	}
+	if len(athenaWrapper.RunningJobs()) != 0 {
+		t.Fatalf("Finished execution must be untracked")
+	}
+}
+
+func TestWaitJobStatusContextCancelled(t *testing.T) {
+	athenaMock := &AthenaMock{
+		GetQueryExecutionOutputCh: makeFinishedExecutionCh("CANCELLED", "Query cancelled by user"),
+	}
+	athenaWrapper := New(athenaMock, "test-db")
+	if _, err := athenaWrapper.ExecuteQuery("dummyQuery", "s3://test-bucket"); err != nil {
+		t.Fatalf("Execute failure %s", err.Error())
+	}
+
+	_, err := athenaWrapper.WaitJobStatusContext(context.Background(), "dummyQueryID", &WaitOptions{
+		InitialInterval: time.Millisecond,
+	})
+	cancelled, ok := err.(*QueryCancelledError)
+	if !ok {
+		t.Fatalf("Wrong error %v", err)
+	}
+	if cancelled.StateChangeReason != "Query cancelled by user" {
+		t.Fatalf("Wrong reason %s", cancelled.StateChangeReason)
+	}
+	if len(athenaWrapper.RunningJobs()) != 0 {
+		t.Fatalf("Finished execution must be untracked")
+	}
+}
@@ -0,0 +1,157 @@
+package athena
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/athena"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultInitialInterval = 1 * time.Second
+	defaultMaxInterval     = 30 * time.Second
+	defaultMultiplier      = 2.0
+)
+
+// WaitOptions is polling policy of waiting for a query execution.
+// The polls which athena throttles are retried at the next interval.
+type WaitOptions struct {
+	// InitialInterval is the first polling interval. Default is 1 second.
+	InitialInterval time.Duration
+	// MaxInterval caps the polling interval. Default is 30 seconds.
+	MaxInterval time.Duration
+	// Multiplier grows the interval after every poll. Default is 2, and 1 keeps the interval fixed.
+	Multiplier float64
+	// Jitter randomizes every interval by the ratio, e.g. 0.2 means +-20%.
+	Jitter float64
+	// MaxWait is the total time limit of waiting. Zero means no limit.
+	MaxWait time.Duration
+	// StopOnCancel stops the query execution when the wait is cancelled or timed out.
+	StopOnCancel bool
+}
+
+func (o *WaitOptions) withDefaults() WaitOptions {
+	opts := WaitOptions{}
+	if o != nil {
+		opts = *o
+	}
+	if opts.InitialInterval <= 0 {
+		opts.InitialInterval = defaultInitialInterval
+	}
+	if opts.MaxInterval <= 0 {
+		opts.MaxInterval = defaultMaxInterval
+	}
+	if opts.MaxInterval < opts.InitialInterval {
+		opts.MaxInterval = opts.InitialInterval
+	}
+	if opts.Multiplier < 1 {
+		opts.Multiplier = defaultMultiplier
+	}
+	if opts.Jitter < 0 {
+		opts.Jitter = 0
+	}
+	if opts.Jitter > 1 {
+		opts.Jitter = 1
+	}
+	return opts
+}
+
+func (o WaitOptions) nextInterval(interval time.Duration) time.Duration {
+	next := time.Duration(float64(interval) * o.Multiplier)
+	if next > o.MaxInterval {
+		return o.MaxInterval
+	}
+	return next
+}
+
+func (o WaitOptions) jittered(interval time.Duration) time.Duration {
+	if o.Jitter == 0 {
+		return interval
+	}
+	delta := o.Jitter * float64(interval)
+	return interval + time.Duration(delta*(2*rand.Float64()-1))
+}
+
+func (s *wrapperAthena) WaitJobStatusContext(ctx context.Context, jobID string, opts *WaitOptions) (bool, error) {
+	if _, err := s.waitExecution(ctx, jobID, opts); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// waitExecution polls the query execution until it reaches a final state
+func (s *wrapperAthena) waitExecution(
+	ctx context.Context, jobID string, opts *WaitOptions) (*athena.QueryExecution, error) {
+
+	policy := opts.withDefaults()
+	if policy.MaxWait > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, policy.MaxWait)
+		defer cancel()
+	}
+
+	interval := policy.InitialInterval
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, s.abortWait(jobID, policy, err)
+		}
+		status, err := s.CheckJobStatus(jobID)
+		switch {
+		case isThrottled(err):
+			// The query keeps running, so the throttled poll is retried after the next interval
+		case err != nil:
+			return nil, errors.Wrap(err, fmt.Sprintf("Check job status failure jobId:%s", jobID))
+		default:
+			if done, err := executionResult(jobID, status.QueryExecution); done {
+				return status.QueryExecution, err
+			}
+		}
+
+		timer := time.NewTimer(policy.jittered(interval))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, s.abortWait(jobID, policy, ctx.Err())
+		case <-timer.C:
+		}
+		interval = policy.nextInterval(interval)
+	}
+}
+
+// executionResult reports whether the wait ends at the execution, and the error of its final state
+func executionResult(jobID string, execution *athena.QueryExecution) (bool, error) {
+	state := ""
+	if execution != nil && execution.Status != nil {
+		state = aws.StringValue(execution.Status.State)
+	}
+	switch state {
+	case athena.QueryExecutionStateSucceeded:
+		return true, nil
+	case athena.QueryExecutionStateQueued, athena.QueryExecutionStateRunning:
+		return false, nil
+	case athena.QueryExecutionStateCancelled:
+		return true, &QueryCancelledError{
+			JobID:             jobID,
+			StateChangeReason: aws.StringValue(execution.Status.StateChangeReason),
+		}
+	case athena.QueryExecutionStateFailed:
+		return true, newQueryFailedError(jobID, execution.Status)
+	}
+	return true, &UnknownStateError{JobID: jobID, State: state}
+}
+
+// abortWait stops the query execution if the policy asks for it and reports why the wait ended
+func (s *wrapperAthena) abortWait(jobID string, policy WaitOptions, cause error) error {
+	message := fmt.Sprintf("Wait job status aborted jobId:%s", jobID)
+	if !policy.StopOnCancel {
+		return errors.Wrap(cause, message)
+	}
+	if err := s.StopQuery(jobID); err != nil {
+		return errors.Wrap(cause, fmt.Sprintf("%s (%s)", message, err.Error()))
+	}
+	return errors.Wrap(cause, message)
+}
@@ -0,0 +1,168 @@
+package athena
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/athena"
+)
+
+func makeStateCh(states ...string) chan *athena.GetQueryExecutionOutput {
+	outputCh := make(chan *athena.GetQueryExecutionOutput, len(states))
+	for _, state := range states {
+		outputCh <- &athena.GetQueryExecutionOutput{
+			QueryExecution: &athena.QueryExecution{
+				Query:            aws.String("dummyQuery"),
+				QueryExecutionId: aws.String("dummyQueryID"),
+				Status: &athena.QueryExecutionStatus{
+					State: aws.String(state),
+				},
+			},
+		}
+	}
+	return outputCh
+}
+
+func TestWaitJobStatusContext(t *testing.T) {
+	athenaMock := &AthenaMock{
+		GetQueryExecutionOutputCh: makeStateCh("QUEUED", "RUNNING", "SUCCEEDED"),
+		GetQueryResultsOutputCh:   makeGetQueryResultsOutputCh(),
+	}
+	defer athenaMock.CloseCh()
+	athenaWrapper := New(athenaMock, "test-db")
+
+	result, err := athenaWrapper.WaitJobStatusContext(context.Background(), "dummyID", &WaitOptions{
+		InitialInterval: time.Millisecond,
+		MaxInterval:     5 * time.Millisecond,
+		Jitter:          0.5,
+	})
+	if err != nil {
+		t.Fatalf("Wait job status failure. %s", err.Error())
+	}
+	if result != true {
+		t.Fatalf("Wrong result %t", result)
+	}
+}
+
+type throttledAthenaMock struct {
+	*AthenaMock
+	throttles int
+	polls     int
+}
+
+func (s *throttledAthenaMock) GetQueryExecution(
+	input *athena.GetQueryExecutionInput) (*athena.GetQueryExecutionOutput, error) {
+
+	s.polls++
+	if s.throttles > 0 {
+		s.throttles--
+		return nil, awserr.New(athena.ErrCodeTooManyRequestsException, "dummy throttle", nil)
+	}
+	return s.AthenaMock.GetQueryExecution(input)
+}
+
+func TestWaitJobStatusContextThrottled(t *testing.T) {
+	athenaMock := &throttledAthenaMock{
+		AthenaMock: &AthenaMock{GetQueryExecutionOutputCh: makeStateCh("RUNNING", "SUCCEEDED")},
+		throttles:  2,
+	}
+	athenaWrapper := New(athenaMock, "test-db")
+
+	_, err := athenaWrapper.WaitJobStatusContext(context.Background(), "dummyID", &WaitOptions{
+		InitialInterval: time.Millisecond,
+		MaxInterval:     time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Throttled poll must be retried %s", err.Error())
+	}
+	if athenaMock.polls != 4 {
+		t.Fatalf("Wrong polls %d", athenaMock.polls)
+	}
+
+	athenaMock.throttles = 1000
+	_, err = athenaWrapper.WaitJobStatusContext(context.Background(), "dummyID", &WaitOptions{
+		InitialInterval: time.Millisecond,
+		MaxWait:         20 * time.Millisecond,
+	})
+	if err == nil {
+		t.Fatalf("Wait must end at max wait while the polls are throttled")
+	}
+}
+
+func TestWaitJobStatusContextFailed(t *testing.T) {
+	athenaMock := &AthenaMock{
+		GetQueryExecutionOutputCh: makeStateCh("RUNNING", "FAILED"),
+		GetQueryResultsOutputCh:   makeGetQueryResultsOutputCh(),
+	}
+	defer athenaMock.CloseCh()
+	athenaWrapper := New(athenaMock, "test-db")
+
+	result, err := athenaWrapper.WaitJobStatusContext(context.Background(), "dummyID", &WaitOptions{
+		InitialInterval: time.Millisecond,
+	})
+	if err == nil {
+		t.Fatalf("Failed job must return error")
+	}
+	if result != false {
+		t.Fatalf("Wrong result %t", result)
+	}
+}
+
+func TestWaitJobStatusContextMaxWait(t *testing.T) {
+	athenaMock := &AthenaMock{
+		GetQueryExecutionOutputCh: makeStateCh("RUNNING", "RUNNING", "RUNNING"),
+		GetQueryResultsOutputCh:   makeGetQueryResultsOutputCh(),
+	}
+	defer athenaMock.CloseCh()
+	athenaWrapper := New(athenaMock, "test-db")
+
+	_, err := athenaWrapper.WaitJobStatusContext(context.Background(), "dummyID", &WaitOptions{
+		InitialInterval: time.Second,
+		MaxWait:         10 * time.Millisecond,
+		StopOnCancel:    true,
+	})
+	if err == nil {
+		t.Fatalf("Timed out wait must return error")
+	}
+	if athenaMock.StopQueryExecutionCounter != 1 {
+		t.Fatalf("Wrong stop query counter %d", athenaMock.StopQueryExecutionCounter)
+	}
+}
+
+func TestWaitJobStatusContextCancel(t *testing.T) {
+	athenaMock := &AthenaMock{
+		GetQueryExecutionOutputCh: makeStateCh("RUNNING"),
+		GetQueryResultsOutputCh:   makeGetQueryResultsOutputCh(),
+	}
+	defer athenaMock.CloseCh()
+	athenaWrapper := New(athenaMock, "test-db")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := athenaWrapper.WaitJobStatusContext(ctx, "dummyID", nil)
+	if err == nil {
+		t.Fatalf("Cancelled wait must return error")
+	}
+	if athenaMock.StopQueryExecutionCounter != 0 {
+		t.Fatalf("Query must not be stopped without StopOnCancel")
+	}
+}
+
+func TestWaitOptionsInterval(t *testing.T) {
+	policy := (&WaitOptions{
+		InitialInterval: time.Second,
+		MaxInterval:     3 * time.Second,
+	}).withDefaults()
+
+	interval := policy.InitialInterval
+	expected := []time.Duration{2 * time.Second, 3 * time.Second, 3 * time.Second}
+	for _, want := range expected {
+		interval = policy.nextInterval(interval)
+		if interval != want {
+			t.Fatalf("Wrong interval. Expected %s but %s", want, interval)
+		}
+	}
+}
@@ -0,0 +1,361 @@
+package dynamo
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/pkg/errors"
+)
+
+const (
+	maxBatchWriteItems        = 25
+	maxBatchGetKeys           = 100
+	defaultBatchConcurrency   = 4
+	defaultBatchMaxRetries    = 5
+	defaultBatchRetryInterval = 100 * time.Millisecond
+	maxBatchRetryInterval     = 5 * time.Second
+)
+
+// BatchOptions is options of BatchPut, BatchDelete and BatchGet
+type BatchOptions struct {
+	// Concurrency is the number of chunks requested at once. Default is 4.
+	Concurrency int
+	// MaxRetries is the number of retries of the unprocessed items and the throttled requests of a chunk. Default is 5.
+	MaxRetries int
+	// RetryInterval is the first interval of the retries, which doubles on every retry. Default is 100 milliseconds.
+	RetryInterval time.Duration
+}
+
+func (o *BatchOptions) withDefaults() BatchOptions {
+	opts := BatchOptions{}
+	if o != nil {
+		opts = *o
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultBatchConcurrency
+	}
+	if opts.MaxRetries <= 0 {
+		opts.MaxRetries = defaultBatchMaxRetries
+	}
+	if opts.RetryInterval <= 0 {
+		opts.RetryInterval = defaultBatchRetryInterval
+	}
+	return opts
+}
+
+// BatchFailure is an item of a batch which never succeeded
+type BatchFailure struct {
+	// Index is the index of the item in the records or keys passed to the batch
+	Index int
+	// Err is ErrUnprocessed when the retries are exhausted, or the error of the request of the chunk
+	Err error
+}
+
+// BatchError is returned when some items of a batch failed. The other items succeeded.
+type BatchError struct {
+	Operation string
+	Table     string
+	// Failures is the failed items in the order of Index
+	Failures []BatchFailure
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("%s failure table=%s failures=%d first=%v",
+		e.Operation, e.Table, len(e.Failures), e.Failures[0].Err)
+}
+
+// BatchPut puts the records, which must be a slice, by BatchWriteItem in chunks of 25 items.
+// Unlike PutItem, conditions and the version field are not checked.
+// It returns *BatchError when some records are not written.
+func (s *wrapperDynamo) BatchPut(ctx context.Context, tableName string, records interface{}, opts *BatchOptions) error {
+	values, err := sliceElements(records)
+	if err != nil {
+		return err
+	}
+	requests := make([]*dynamodb.WriteRequest, len(values))
+	for i, record := range values {
+		item, err := dynamodbattribute.MarshalMap(record)
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("Marshal record failure index=%d", i))
+		}
+		requests[i] = &dynamodb.WriteRequest{PutRequest: &dynamodb.PutRequest{Item: item}}
+	}
+	return s.batchWrite(ctx, "Batch put", tableName, requests, opts.withDefaults())
+}
+
+// BatchDelete deletes the items of the keys, which must be a slice, by BatchWriteItem in chunks of 25 items.
+// See GetItemWithKey about the keys. It returns *BatchError when some items are not deleted.
+func (s *wrapperDynamo) BatchDelete(ctx context.Context, tableName string, keys interface{}, opts *BatchOptions) error {
+	keyValues, err := marshalKeys(keys)
+	if err != nil {
+		return err
+	}
+	requests := make([]*dynamodb.WriteRequest, len(keyValues))
+	for i, key := range keyValues {
+		requests[i] = &dynamodb.WriteRequest{DeleteRequest: &dynamodb.DeleteRequest{Key: key}}
+	}
+	return s.batchWrite(ctx, "Batch delete", tableName, requests, opts.withDefaults())
+}
+
+// BatchGet unmarshals the items of the keys, which must be a slice, into out, which must be a pointer
+// to a slice, by BatchGetItem in chunks of 100 keys. The order of the items is not kept, and the keys
+// without items are skipped. WithConsistentRead and WithProjection are available as readOpts,
+// and the other options are error.
+// It returns *BatchError when some keys are not read, after out is set to the items which are read.
+func (s *wrapperDynamo) BatchGet(ctx context.Context,
+	tableName string, keys interface{}, out interface{}, opts *BatchOptions, readOpts ...ReadOption) error {
+
+	keyValues, err := marshalKeys(keys)
+	if err != nil {
+		return err
+	}
+	readConf := newReadConfig(readOpts)
+	if err := readConf.checkOptions("BatchGet", "WithConsistentRead", "WithProjection"); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("Invalid read option table=%s", tableName))
+	}
+	expr, err := readConf.expression(nil)
+	if err != nil {
+		return err
+	}
+	conf := opts.withDefaults()
+	result := &batchResult{}
+	runChunks(len(keyValues), maxBatchGetKeys, conf.Concurrency, func(start int, end int) {
+		pending := indexRange(start, end)
+		for attempt := 0; ; attempt++ {
+			if err := conf.wait(ctx, attempt); err != nil {
+				result.fail(pending, err)
+				return
+			}
+			chunk := &dynamodb.KeysAndAttributes{Keys: make([]map[string]*dynamodb.AttributeValue, len(pending))}
+			for i, index := range pending {
+				chunk.Keys[i] = keyValues[index]
+			}
+			if readConf.consistentRead {
+				chunk.ConsistentRead = aws.Bool(true)
+			}
+			if expr != nil {
+				chunk.ProjectionExpression = expr.Projection()
+				chunk.ExpressionAttributeNames = expr.Names()
+			}
+			output, err := s.Client.BatchGetItem(&dynamodb.BatchGetItemInput{
+				RequestItems: map[string]*dynamodb.KeysAndAttributes{tableName: chunk},
+			})
+			if err != nil {
+				if isThrottled(err) && attempt < conf.MaxRetries {
+					continue
+				}
+				result.fail(pending, errors.Wrap(err, fmt.Sprintf("Batch get item failure table=%s", tableName)))
+				return
+			}
+			result.add(output.Responses[tableName])
+			var unprocessed []map[string]*dynamodb.AttributeValue
+			if output.UnprocessedKeys[tableName] != nil {
+				unprocessed = output.UnprocessedKeys[tableName].Keys
+			}
+			pending = remaining(pending, len(unprocessed), func(index int) bool {
+				return containsValue(unprocessed, keyValues[index])
+			})
+			if len(pending) == 0 {
+				return
+			}
+			if attempt >= conf.MaxRetries {
+				result.fail(pending, ErrUnprocessed)
+				return
+			}
+		}
+	})
+
+	if err := dynamodbattribute.UnmarshalListOfMaps(result.items, out); err != nil {
+		return errors.Wrap(err, "Unmarshal items failure")
+	}
+	return result.err("Batch get", tableName)
+}
+
+// batchWrite writes the requests in chunks, retrying the unprocessed items
+func (s *wrapperDynamo) batchWrite(
+	ctx context.Context, operation string, tableName string, requests []*dynamodb.WriteRequest, conf BatchOptions) error {
+
+	result := &batchResult{}
+	runChunks(len(requests), maxBatchWriteItems, conf.Concurrency, func(start int, end int) {
+		pending := indexRange(start, end)
+		for attempt := 0; ; attempt++ {
+			if err := conf.wait(ctx, attempt); err != nil {
+				result.fail(pending, err)
+				return
+			}
+			chunk := make([]*dynamodb.WriteRequest, len(pending))
+			for i, index := range pending {
+				chunk[i] = requests[index]
+			}
+			output, err := s.Client.BatchWriteItem(&dynamodb.BatchWriteItemInput{
+				RequestItems: map[string][]*dynamodb.WriteRequest{tableName: chunk},
+			})
+			if err != nil {
+				if isThrottled(err) && attempt < conf.MaxRetries {
+					continue
+				}
+				result.fail(pending, errors.Wrap(err, fmt.Sprintf("Batch write item failure table=%s", tableName)))
+				return
+			}
+			unprocessed := output.UnprocessedItems[tableName]
+			pending = remaining(pending, len(unprocessed), func(index int) bool {
+				return containsValue(unprocessed, requests[index])
+			})
+			if len(pending) == 0 {
+				return
+			}
+			if attempt >= conf.MaxRetries {
+				result.fail(pending, ErrUnprocessed)
+				return
+			}
+		}
+	})
+	return result.err(operation, tableName)
+}
+
+// wait sleeps before the retry of the attempt by the exponential backoff with full jitter.
+// It returns the error of ctx when ctx is done.
+func (o BatchOptions) wait(ctx context.Context, attempt int) error {
+	if attempt == 0 {
+		return ctx.Err()
+	}
+	interval := o.RetryInterval
+	for i := 1; i < attempt && interval < maxBatchRetryInterval; i++ {
+		interval *= 2
+	}
+	if interval > maxBatchRetryInterval {
+		interval = maxBatchRetryInterval
+	}
+	timer := time.NewTimer(time.Duration(rand.Int63n(int64(interval)) + 1))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// runChunks calls fn with the ranges of at most size over n items, running concurrency calls at once
+func runChunks(n int, size int, concurrency int, fn func(start int, end int)) {
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for start := 0; start < n; start += size {
+		end := start + size
+		if end > n {
+			end = n
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(start int, end int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(start, end)
+		}(start, end)
+	}
+	wg.Wait()
+}
+
+// batchResult collects the items and the failures of the chunks
+type batchResult struct {
+	mu       sync.Mutex
+	items    []map[string]*dynamodb.AttributeValue
+	failures []BatchFailure
+}
+
+func (r *batchResult) add(items []map[string]*dynamodb.AttributeValue) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.items = append(r.items, items...)
+}
+
+func (r *batchResult) fail(indexes []int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, index := range indexes {
+		r.failures = append(r.failures, BatchFailure{Index: index, Err: err})
+	}
+}
+
+// err returns *BatchError of the failures, or nil when every item succeeded
+func (r *batchResult) err(operation string, tableName string) error {
+	if len(r.failures) == 0 {
+		return nil
+	}
+	sort.Slice(r.failures, func(i, j int) bool { return r.failures[i].Index < r.failures[j].Index })
+	return &BatchError{Operation: operation, Table: tableName, Failures: r.failures}
+}
+
+func indexRange(start int, end int) []int {
+	indexes := make([]int, 0, end-start)
+	for i := start; i < end; i++ {
+		indexes = append(indexes, i)
+	}
+	return indexes
+}
+
+// remaining returns the pending indexes of the unprocessed items. The unprocessed items are the copies
+// of the requested ones, so they are matched by the values. When some of them are not matched,
+// all the pending items are retried, which is harmless as puts, deletes and gets are idempotent.
+func remaining(pending []int, unprocessed int, isUnprocessed func(index int) bool) []int {
+	if unprocessed == 0 {
+		return nil
+	}
+	next := []int{}
+	for _, index := range pending {
+		if isUnprocessed(index) {
+			next = append(next, index)
+		}
+	}
+	if len(next) < unprocessed {
+		return pending
+	}
+	return next
+}
+
+// containsValue reports whether the slice has the element deeply equal to the value
+func containsValue(slice interface{}, value interface{}) bool {
+	v := reflect.ValueOf(slice)
+	for i := 0; i < v.Len(); i++ {
+		if reflect.DeepEqual(v.Index(i).Interface(), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// sliceElements returns the elements of the slice or array
+func sliceElements(values interface{}) ([]interface{}, error) {
+	v := reflect.ValueOf(values)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, errors.New(fmt.Sprintf("Batch values must be slice values=%T", values))
+	}
+	elements := make([]interface{}, v.Len())
+	for i := range elements {
+		elements[i] = v.Index(i).Interface()
+	}
+	return elements, nil
+}
+
+// marshalKeys returns the attribute values of the keys, which must be a slice
+func marshalKeys(keys interface{}) ([]map[string]*dynamodb.AttributeValue, error) {
+	values, err := sliceElements(keys)
+	if err != nil {
+		return nil, err
+	}
+	keyValues := make([]map[string]*dynamodb.AttributeValue, len(values))
+	for i, key := range values {
+		if keyValues[i], err = marshalKey(key); err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("Invalid key index=%d", i))
+		}
+	}
+	return keyValues, nil
+}
@@ -0,0 +1,255 @@
+package dynamo
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// batchDynamoMock returns the items of the ids in Unprocessable as unprocessed,
+// and throttles the first requests
+type batchDynamoMock struct {
+	*DynamoMock
+	mu            sync.Mutex
+	Unprocessable map[string]bool
+	Throttles     int
+	WriteChunks   [][]*dynamodb.WriteRequest
+	GetChunks     []*dynamodb.KeysAndAttributes
+	Written       map[string]bool
+}
+
+// copyValue returns the copy of the value through JSON as the responses of the sdk are
+func copyValue(value interface{}, out interface{}) {
+	data, _ := json.Marshal(value)
+	json.Unmarshal(data, out)
+}
+
+func (s *batchDynamoMock) throttle() error {
+	if s.Throttles > 0 {
+		s.Throttles--
+		return awserr.New(dynamodb.ErrCodeProvisionedThroughputExceededException, "dummy throttle", nil)
+	}
+	return nil
+}
+
+func (s *batchDynamoMock) BatchWriteItem(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.throttle(); err != nil {
+		return nil, err
+	}
+	chunk := input.RequestItems["test-table"]
+	s.WriteChunks = append(s.WriteChunks, chunk)
+	unprocessed := []*dynamodb.WriteRequest{}
+	for _, request := range chunk {
+		var id string
+		if request.PutRequest != nil {
+			id = aws.StringValue(request.PutRequest.Item["id"].S)
+		} else {
+			id = aws.StringValue(request.DeleteRequest.Key["id"].S)
+		}
+		if s.Unprocessable[id] {
+			var copied dynamodb.WriteRequest
+			copyValue(request, &copied)
+			unprocessed = append(unprocessed, &copied)
+			continue
+		}
+		s.Written[id] = true
+	}
+	output := &dynamodb.BatchWriteItemOutput{}
+	if len(unprocessed) > 0 {
+		output.UnprocessedItems = map[string][]*dynamodb.WriteRequest{"test-table": unprocessed}
+	}
+	return output, nil
+}
+
+func (s *batchDynamoMock) BatchGetItem(input *dynamodb.BatchGetItemInput) (*dynamodb.BatchGetItemOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.throttle(); err != nil {
+		return nil, err
+	}
+	chunk := input.RequestItems["test-table"]
+	s.GetChunks = append(s.GetChunks, chunk)
+	output := &dynamodb.BatchGetItemOutput{Responses: map[string][]map[string]*dynamodb.AttributeValue{}}
+	unprocessed := []map[string]*dynamodb.AttributeValue{}
+	for _, key := range chunk.Keys {
+		id := aws.StringValue(key["id"].S)
+		if s.Unprocessable[id] {
+			var copied map[string]*dynamodb.AttributeValue
+			copyValue(key, &copied)
+			unprocessed = append(unprocessed, copied)
+			continue
+		}
+		for _, item := range s.Items {
+			if aws.StringValue(item["id"].S) == id {
+				output.Responses["test-table"] = append(output.Responses["test-table"], item)
+			}
+		}
+	}
+	if len(unprocessed) > 0 {
+		output.UnprocessedKeys = map[string]*dynamodb.KeysAndAttributes{"test-table": {Keys: unprocessed}}
+	}
+	return output, nil
+}
+
+func makeRecords(n int) []TestRecord {
+	records := []TestRecord{}
+	for i := 0; i < n; i++ {
+		records = append(records, TestRecord{ID: "id" + strconv.Itoa(i), DescText: "text" + strconv.Itoa(i)})
+	}
+	return records
+}
+
+func TestBatchPut(t *testing.T) {
+	dynamoMock := &batchDynamoMock{DynamoMock: &DynamoMock{}, Throttles: 2, Written: map[string]bool{}}
+	dynamoDBClient := New(dynamoMock)
+
+	err := dynamoDBClient.BatchPut(context.Background(), "test-table", makeRecords(60), &BatchOptions{
+		Concurrency:   2,
+		RetryInterval: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Batch put failure %s", err.Error())
+	}
+	if len(dynamoMock.Written) != 60 {
+		t.Fatalf("Every record must be written written=%d", len(dynamoMock.Written))
+	}
+	if len(dynamoMock.WriteChunks) != 3 {
+		t.Fatalf("Records must be written in chunks of 25 chunks=%d", len(dynamoMock.WriteChunks))
+	}
+	for _, chunk := range dynamoMock.WriteChunks {
+		if len(chunk) > maxBatchWriteItems {
+			t.Fatalf("Chunk is too large size=%d", len(chunk))
+		}
+	}
+}
+
+func TestBatchPutUnprocessed(t *testing.T) {
+	dynamoMock := &batchDynamoMock{
+		DynamoMock:    &DynamoMock{},
+		Unprocessable: map[string]bool{"id3": true, "id27": true},
+		Written:       map[string]bool{},
+	}
+	dynamoDBClient := New(dynamoMock)
+
+	err := dynamoDBClient.BatchPut(context.Background(), "test-table", makeRecords(30), &BatchOptions{
+		MaxRetries:    2,
+		RetryInterval: time.Millisecond,
+	})
+	batchErr, ok := err.(*BatchError)
+	if !ok {
+		t.Fatalf("Unprocessed records must be BatchError err=%v", err)
+	}
+	if len(batchErr.Failures) != 2 || batchErr.Failures[0].Index != 3 || batchErr.Failures[1].Index != 27 {
+		t.Fatalf("Invalid failures %+v", batchErr.Failures)
+	}
+	if batchErr.Failures[0].Err != ErrUnprocessed {
+		t.Fatalf("Failure must be ErrUnprocessed err=%v", batchErr.Failures[0].Err)
+	}
+	if len(dynamoMock.Written) != 28 {
+		t.Fatalf("Other records must be written written=%d", len(dynamoMock.Written))
+	}
+	// 2 chunks of the first attempt and 2 retries of each chunk
+	if len(dynamoMock.WriteChunks) != 6 {
+		t.Fatalf("Only unprocessed records must be retried chunks=%d", len(dynamoMock.WriteChunks))
+	}
+	retries := 0
+	for _, chunk := range dynamoMock.WriteChunks {
+		if len(chunk) == 1 {
+			retries++
+		}
+	}
+	if retries != 4 {
+		t.Fatalf("Retry must contain only unprocessed records retries=%d", retries)
+	}
+}
+
+func TestBatchDelete(t *testing.T) {
+	dynamoMock := &batchDynamoMock{DynamoMock: &DynamoMock{}, Written: map[string]bool{}}
+	dynamoDBClient := New(dynamoMock)
+
+	keys := []Key{NewKey("id", "id0"), NewKey("id", "id1")}
+	if err := dynamoDBClient.BatchDelete(context.Background(), "test-table", keys, nil); err != nil {
+		t.Fatalf("Batch delete failure %s", err.Error())
+	}
+	chunk := dynamoMock.WriteChunks[0]
+	if len(chunk) != 2 || aws.StringValue(chunk[1].DeleteRequest.Key["id"].S) != "id1" {
+		t.Fatalf("Invalid delete requests %v", chunk)
+	}
+
+	if err := dynamoDBClient.BatchDelete(context.Background(), "test-table", NewKey("id", "id0"), nil); err == nil {
+		t.Fatalf("Non slice keys must be error")
+	}
+}
+
+func TestBatchGet(t *testing.T) {
+	dynamoMock := &batchDynamoMock{
+		DynamoMock:    &DynamoMock{Items: makeItems(250)},
+		Unprocessable: map[string]bool{"id120": true},
+	}
+	dynamoDBClient := New(dynamoMock)
+
+	keys := []Key{}
+	for i := 0; i < 250; i++ {
+		keys = append(keys, NewKey("id", "id"+strconv.Itoa(i)))
+	}
+	keys = append(keys, NewKey("id", "missing"))
+	records := []TestRecord{}
+	err := dynamoDBClient.BatchGet(context.Background(), "test-table", keys, &records,
+		&BatchOptions{MaxRetries: 1, RetryInterval: time.Millisecond}, WithConsistentRead(), WithProjection("id"))
+	batchErr, ok := err.(*BatchError)
+	if !ok || len(batchErr.Failures) != 1 || batchErr.Failures[0].Index != 120 {
+		t.Fatalf("Unprocessed key must be reported err=%v", err)
+	}
+	if len(records) != 249 {
+		t.Fatalf("Read items must be set despite the failure records=%d", len(records))
+	}
+	// 3 chunks of 100 keys and a retry of the unprocessed key
+	if len(dynamoMock.GetChunks) != 4 {
+		t.Fatalf("Keys must be read in chunks of 100 chunks=%d", len(dynamoMock.GetChunks))
+	}
+	chunk := dynamoMock.GetChunks[0]
+	if !aws.BoolValue(chunk.ConsistentRead) || aws.StringValue(chunk.ProjectionExpression) != "#0" {
+		t.Fatalf("Read options must be set %v", chunk)
+	}
+}
+
+func TestBatchGetInvalidOptions(t *testing.T) {
+	dynamoMock := &batchDynamoMock{DynamoMock: &DynamoMock{Items: makeItems(1)}}
+	dynamoDBClient := New(dynamoMock)
+
+	records := []TestRecord{}
+	for _, opt := range []ReadOption{WithIndex("by-text"), WithLimit(1), WithSegments(2)} {
+		err := dynamoDBClient.BatchGet(context.Background(), "test-table", []Key{NewKey("id", "id0")}, &records, nil, opt)
+		if err == nil {
+			t.Fatalf("Unsupported read option must be error")
+		}
+	}
+	if len(dynamoMock.GetChunks) != 0 {
+		t.Fatalf("Items must not be read with unsupported option")
+	}
+}
+
+func TestBatchCancelled(t *testing.T) {
+	dynamoMock := &batchDynamoMock{DynamoMock: &DynamoMock{}, Written: map[string]bool{}}
+	dynamoDBClient := New(dynamoMock)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := dynamoDBClient.BatchPut(ctx, "test-table", makeRecords(30), nil)
+	batchErr, ok := err.(*BatchError)
+	if !ok || len(batchErr.Failures) != 30 || batchErr.Failures[0].Err != context.Canceled {
+		t.Fatalf("Cancelled batch must fail every record err=%v", err)
+	}
+	if len(dynamoMock.WriteChunks) != 0 {
+		t.Fatalf("Cancelled batch must not call dynamodb")
+	}
+}
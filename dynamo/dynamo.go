@@ -1,23 +1,47 @@
 package dynamo
 
 import (
+	"context"
 	"fmt"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
 	"github.com/pkg/errors"
 )
 
 // WrapperDynamo is wrapper of client of aws dynamodb
 type WrapperDynamo interface {
 	GetItem(tableName string, key string, val string) (*dynamodb.GetItemOutput, error)
-	PutItem(tableName string, record interface{}) (*dynamodb.PutItemOutput, error)
+	GetItemWithKey(tableName string, key interface{}, opts ...ReadOption) (*dynamodb.GetItemOutput, error)
+	GetItemInto(ctx context.Context, tableName string, key interface{}, out interface{}, opts ...ReadOption) error
+	PutItem(tableName string, record interface{}, opts ...WriteOption) (*dynamodb.PutItemOutput, error)
+	DeleteItem(tableName string, key interface{}, opts ...WriteOption) (*dynamodb.DeleteItemOutput, error)
+	UpdateItem(tableName string, key interface{}, update expression.UpdateBuilder, opts ...WriteOption) (*dynamodb.UpdateItemOutput, error)
+	ApplyUpdate(tableName string, key interface{}, update *Update, opts ...WriteOption) (*dynamodb.UpdateItemOutput, error)
+	Query(ctx context.Context, tableName string, keyCondition expression.KeyConditionBuilder, out interface{}, opts ...ReadOption) error
+	QueryIterator(ctx context.Context, tableName string, keyCondition expression.KeyConditionBuilder, opts ...ReadOption) *ItemIterator
+	Scan(ctx context.Context, tableName string, out interface{}, opts ...ReadOption) error
+	ScanIterator(ctx context.Context, tableName string, opts ...ReadOption) *ItemIterator
+	BatchPut(ctx context.Context, tableName string, records interface{}, opts *BatchOptions) error
+	BatchDelete(ctx context.Context, tableName string, keys interface{}, opts *BatchOptions) error
+	BatchGet(ctx context.Context, tableName string, keys interface{}, out interface{}, opts *BatchOptions, readOpts ...ReadOption) error
+	TransactWrite(ctx context.Context, tx *WriteTransaction) (*dynamodb.TransactWriteItemsOutput, error)
+	TransactGet(ctx context.Context, get *GetTransaction) error
 }
 
 // AWSDynamo is interface of aws dynamodb
 type AWSDynamo interface {
 	GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error)
 	PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error)
+	DeleteItem(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error)
+	UpdateItem(input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error)
+	Query(input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error)
+	Scan(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error)
+	BatchWriteItem(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error)
+	BatchGetItem(input *dynamodb.BatchGetItemInput) (*dynamodb.BatchGetItemOutput, error)
+	TransactWriteItems(input *dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsOutput, error)
+	TransactGetItems(input *dynamodb.TransactGetItemsInput) (*dynamodb.TransactGetItemsOutput, error)
 }
 
 type wrapperDynamo struct {
@@ -25,31 +49,163 @@ type wrapperDynamo struct {
 }
 
 func (s *wrapperDynamo) GetItem(tableName string, key string, val string) (*dynamodb.GetItemOutput, error) {
-	return s.Client.GetItem(&dynamodb.GetItemInput{
-		TableName: aws.String(tableName),
-		Key: map[string]*dynamodb.AttributeValue{
-			key: {
-				S: aws.String(val),
-			},
-		},
-	})
+	return s.GetItemWithKey(tableName, NewKey(key, val))
+}
+
+// GetItemWithKey gets the item by the key. key is Key, the attribute values of the key,
+// or a struct or map which dynamodbattribute marshals into only the key attributes.
+// WithConsistentRead and WithProjection are available as opts, and the other options are error.
+func (s *wrapperDynamo) GetItemWithKey(
+	tableName string, key interface{}, opts ...ReadOption) (*dynamodb.GetItemOutput, error) {
+
+	keyValues, err := marshalKey(key)
+	if err != nil {
+		return nil, err
+	}
+	input, err := newReadConfig(opts).getItemInput(tableName, keyValues)
+	if err != nil {
+		return nil, err
+	}
+	output, err := s.Client.GetItem(input)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("Get item failure table=%s", tableName))
+	}
+	return output, nil
+}
+
+// GetItemInto gets the item by the key and unmarshals it into out, which must be a pointer.
+// It returns ErrNotFound when the item does not exist.
+func (s *wrapperDynamo) GetItemInto(
+	ctx context.Context, tableName string, key interface{}, out interface{}, opts ...ReadOption) error {
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	output, err := s.GetItemWithKey(tableName, key, opts...)
+	if err != nil {
+		return err
+	}
+	if len(output.Item) == 0 {
+		return ErrNotFound
+	}
+	if err := dynamodbattribute.UnmarshalMap(output.Item, out); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("Unmarshal item failure table=%s", tableName))
+	}
+	return nil
 }
 
-func (s *wrapperDynamo) PutItem(tableName string, record interface{}) (*dynamodb.PutItemOutput, error) {
+// PutItem puts the record marshaled by dynamodbattribute.
+// When the record has an integer field tagged `dynamo:"version"`, the put is optimistically locked:
+// it succeeds only when the stored version equals the field, or the item does not exist for zero,
+// and writes the incremented version, which is also set into the record if it is a pointer.
+// It returns ErrConditionFailed on a conflict or when a condition of opts is not satisfied.
+func (s *wrapperDynamo) PutItem(tableName string, record interface{}, opts ...WriteOption) (*dynamodb.PutItemOutput, error) {
 	item, err := dynamodbattribute.MarshalMap(record)
 	if err != nil {
 		errMessage := fmt.Sprintf("Marshal put item failure record=%+v", record)
 		return nil, errors.Wrap(err, errMessage)
 	}
-	result, err := s.Client.PutItem(&dynamodb.PutItemInput{
-		TableName: aws.String(tableName),
-		Item:      item,
-	})
+	conf := newWriteConfig(opts)
+	lock, err := newVersionLock(record)
+	if err != nil {
+		return nil, err
+	}
+	if lock != nil {
+		lock.apply(item)
+		conf.conditions = append(conf.conditions, lock.condition())
+	}
+	expr, err := conf.expression(nil)
+	if err != nil {
+		return nil, err
+	}
+	input := &dynamodb.PutItemInput{
+		TableName:    aws.String(tableName),
+		Item:         item,
+		ReturnValues: conf.returnValuesInput(),
+	}
+	if expr != nil {
+		input.ConditionExpression = expr.Condition()
+		input.ExpressionAttributeNames = expr.Names()
+		input.ExpressionAttributeValues = expr.Values()
+	}
+	result, err := s.Client.PutItem(input)
 	if err != nil {
+		if isConditionFailed(err) {
+			return nil, ErrConditionFailed
+		}
 		errMessage := fmt.Sprintf("Marshal put item failure item=%+v", item)
 		return nil, errors.Wrap(err, errMessage)
 	}
-	return result, errors.Wrap(err, "Put item failure")
+	if lock != nil {
+		lock.commit(record)
+	}
+	return result, conf.unmarshalReturned(result.Attributes)
+}
+
+// DeleteItem deletes the item of the key. See GetItemWithKey about key.
+// It returns ErrConditionFailed when a condition of opts is not satisfied.
+func (s *wrapperDynamo) DeleteItem(
+	tableName string, key interface{}, opts ...WriteOption) (*dynamodb.DeleteItemOutput, error) {
+
+	keyValues, err := marshalKey(key)
+	if err != nil {
+		return nil, err
+	}
+	conf := newWriteConfig(opts)
+	expr, err := conf.expression(nil)
+	if err != nil {
+		return nil, err
+	}
+	input := &dynamodb.DeleteItemInput{
+		TableName:    aws.String(tableName),
+		Key:          keyValues,
+		ReturnValues: conf.returnValuesInput(),
+	}
+	if expr != nil {
+		input.ConditionExpression = expr.Condition()
+		input.ExpressionAttributeNames = expr.Names()
+		input.ExpressionAttributeValues = expr.Values()
+	}
+	output, err := s.Client.DeleteItem(input)
+	if err != nil {
+		if isConditionFailed(err) {
+			return nil, ErrConditionFailed
+		}
+		return nil, errors.Wrap(err, fmt.Sprintf("Delete item failure table=%s", tableName))
+	}
+	return output, conf.unmarshalReturned(output.Attributes)
+}
+
+// UpdateItem updates the attributes of the item of the key by the update expression.
+// See GetItemWithKey about key. It returns ErrConditionFailed when a condition of opts is not satisfied.
+func (s *wrapperDynamo) UpdateItem(
+	tableName string, key interface{}, update expression.UpdateBuilder, opts ...WriteOption) (*dynamodb.UpdateItemOutput, error) {
+
+	keyValues, err := marshalKey(key)
+	if err != nil {
+		return nil, err
+	}
+	conf := newWriteConfig(opts)
+	expr, err := conf.expression(&update)
+	if err != nil {
+		return nil, err
+	}
+	output, err := s.Client.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName:                 aws.String(tableName),
+		Key:                       keyValues,
+		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		ReturnValues:              conf.returnValuesInput(),
+	})
+	if err != nil {
+		if isConditionFailed(err) {
+			return nil, ErrConditionFailed
+		}
+		return nil, errors.Wrap(err, fmt.Sprintf("Update item failure table=%s", tableName))
+	}
+	return output, conf.unmarshalReturned(output.Attributes)
 }
 
 // New is return instance of wrapper of dynamodb client
@@ -1,9 +1,14 @@
 package dynamo
 
 import (
+	"context"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"strconv"
+	"sync"
 	"testing"
 )
 
@@ -12,9 +17,32 @@ type TestRecord struct {
 	DescText string `json:"desc_text"`
 }
 
-type DynamoMock struct {}
+type DynamoMock struct {
+	GetItemInput *dynamodb.GetItemInput
+	NotFound bool
+	Item map[string]*dynamodb.AttributeValue
+	PutItemInput *dynamodb.PutItemInput
+	DeleteItemInput *dynamodb.DeleteItemInput
+	UpdateItemInput *dynamodb.UpdateItemInput
+	WriteErr error
+	ReturnAttributes map[string]*dynamodb.AttributeValue
+	Items []map[string]*dynamodb.AttributeValue
+	QueryInputs []*dynamodb.QueryInput
+	ScanInputs []*dynamodb.ScanInput
+	ReadErr error
+	TransactWriteInput *dynamodb.TransactWriteItemsInput
+	TransactGetInput *dynamodb.TransactGetItemsInput
+	mu sync.Mutex
+}
 
 func (s *DynamoMock) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	s.GetItemInput = input
+	if s.NotFound {
+		return &dynamodb.GetItemOutput{}, nil
+	}
+	if s.Item != nil {
+		return &dynamodb.GetItemOutput{Item: s.Item}, nil
+	}
 	return &dynamodb.GetItemOutput{
 		Item: map[string]*dynamodb.AttributeValue{
 			"id": {
@@ -28,11 +56,126 @@ func (s *DynamoMock) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOut
 }
 
 func (s *DynamoMock) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	s.PutItemInput = input
+	if s.WriteErr != nil {
+		return nil, s.WriteErr
+	}
 	return &dynamodb.PutItemOutput{
-
+		Attributes: s.ReturnAttributes,
 	}, nil
 }
 
+func (s *DynamoMock) DeleteItem(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+	s.DeleteItemInput = input
+	if s.WriteErr != nil {
+		return nil, s.WriteErr
+	}
+	return &dynamodb.DeleteItemOutput{Attributes: s.ReturnAttributes}, nil
+}
+
+func (s *DynamoMock) UpdateItem(input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+	s.UpdateItemInput = input
+	if s.WriteErr != nil {
+		return nil, s.WriteErr
+	}
+	return &dynamodb.UpdateItemOutput{Attributes: s.ReturnAttributes}, nil
+}
+
+// pageItems returns the items from the offset of the start key by pages of limit, default 2
+func pageItems(items []map[string]*dynamodb.AttributeValue, startKey map[string]*dynamodb.AttributeValue,
+	limit *int64) ([]map[string]*dynamodb.AttributeValue, map[string]*dynamodb.AttributeValue) {
+	start := 0
+	if startKey != nil {
+		start, _ = strconv.Atoi(aws.StringValue(startKey["offset"].N))
+	}
+	end := start + 2
+	if limit != nil {
+		end = start + int(*limit)
+	}
+	if end >= len(items) {
+		return items[start:], nil
+	}
+	return items[start:end], map[string]*dynamodb.AttributeValue{"offset": {N: aws.String(strconv.Itoa(end))}}
+}
+
+// Query returns Items by pages without evaluating the key condition
+func (s *DynamoMock) Query(input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copied := *input
+	s.QueryInputs = append(s.QueryInputs, &copied)
+	if s.ReadErr != nil {
+		return nil, s.ReadErr
+	}
+	items, lastKey := pageItems(s.Items, input.ExclusiveStartKey, input.Limit)
+	return &dynamodb.QueryOutput{Items: items, LastEvaluatedKey: lastKey}, nil
+}
+
+// Scan returns Items by pages, where the segment of the item is its index modulo the total segments
+func (s *DynamoMock) Scan(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copied := *input
+	s.ScanInputs = append(s.ScanInputs, &copied)
+	if s.ReadErr != nil {
+		return nil, s.ReadErr
+	}
+	segmentItems := s.Items
+	if input.TotalSegments != nil {
+		segmentItems = nil
+		for i, item := range s.Items {
+			if int64(i)%*input.TotalSegments == *input.Segment {
+				segmentItems = append(segmentItems, item)
+			}
+		}
+	}
+	items, lastKey := pageItems(segmentItems, input.ExclusiveStartKey, input.Limit)
+	return &dynamodb.ScanOutput{Items: items, LastEvaluatedKey: lastKey}, nil
+}
+
+func (s *DynamoMock) BatchWriteItem(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+	return &dynamodb.BatchWriteItemOutput{}, nil
+}
+
+func (s *DynamoMock) BatchGetItem(input *dynamodb.BatchGetItemInput) (*dynamodb.BatchGetItemOutput, error) {
+	return &dynamodb.BatchGetItemOutput{}, nil
+}
+
+func (s *DynamoMock) TransactWriteItems(input *dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsOutput, error) {
+	s.TransactWriteInput = input
+	if s.WriteErr != nil {
+		return nil, s.WriteErr
+	}
+	return &dynamodb.TransactWriteItemsOutput{}, nil
+}
+
+func (s *DynamoMock) TransactGetItems(input *dynamodb.TransactGetItemsInput) (*dynamodb.TransactGetItemsOutput, error) {
+	s.TransactGetInput = input
+	if s.ReadErr != nil {
+		return nil, s.ReadErr
+	}
+	output := &dynamodb.TransactGetItemsOutput{}
+	for i := range input.TransactItems {
+		response := &dynamodb.ItemResponse{}
+		if i < len(s.Items) {
+			response.Item = s.Items[i]
+		}
+		output.Responses = append(output.Responses, response)
+	}
+	return output, nil
+}
+
+func makeItems(n int) []map[string]*dynamodb.AttributeValue {
+	items := []map[string]*dynamodb.AttributeValue{}
+	for i := 0; i < n; i++ {
+		items = append(items, map[string]*dynamodb.AttributeValue{
+			"id":        {S: aws.String("id" + strconv.Itoa(i))},
+			"desc_text": {S: aws.String("text" + strconv.Itoa(i))},
+		})
+	}
+	return items
+}
+
 func TestPutItem(t *testing.T) {
 	dummyTableName := "test-table"
 
@@ -63,3 +206,88 @@ func TestPutItem(t *testing.T) {
 		t.Fatalf("Wrong desc text. Expected %s but %s", "dummyText", record.DescText)
 	}
 }
+
+func TestGetItemInto(t *testing.T) {
+	dynamoMock := &DynamoMock{}
+	dynamoDBClient := New(dynamoMock)
+
+	var record TestRecord
+	if err := dynamoDBClient.GetItemInto(context.Background(), "test-table", NewKey("id", "dummyId"), &record); err != nil {
+		t.Fatalf("Get item failure %s", err.Error())
+	}
+	if record.ID != "dummyId" || record.DescText != "dummyText" {
+		t.Fatalf("Invalid record %+v", record)
+	}
+
+	if err := dynamoDBClient.GetItemInto(context.Background(), "test-table", NewKey("id", "dummyId"), record); err == nil {
+		t.Fatalf("Non pointer destination must be error")
+	}
+
+	dynamoMock.NotFound = true
+	err := dynamoDBClient.GetItemInto(context.Background(), "test-table", NewKey("id", "missing"), &record)
+	if err != ErrNotFound {
+		t.Fatalf("Missing item must be ErrNotFound err=%v", err)
+	}
+}
+
+func TestGetItemIntoCancelled(t *testing.T) {
+	dynamoMock := &DynamoMock{}
+	dynamoDBClient := New(dynamoMock)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var record TestRecord
+	if err := dynamoDBClient.GetItemInto(ctx, "test-table", NewKey("id", "dummyId"), &record); err != context.Canceled {
+		t.Fatalf("Cancelled context must be error err=%v", err)
+	}
+	if dynamoMock.GetItemInput != nil {
+		t.Fatalf("Cancelled get must not call dynamodb")
+	}
+}
+
+func TestDeleteItem(t *testing.T) {
+	dynamoMock := &DynamoMock{}
+	dynamoDBClient := New(dynamoMock)
+
+	_, err := dynamoDBClient.DeleteItem("test-table", NewKey("id", "dummyId"), IfExists("id"))
+	if err != nil {
+		t.Fatalf("Delete item failure %s", err.Error())
+	}
+	input := dynamoMock.DeleteItemInput
+	if aws.StringValue(input.Key["id"].S) != "dummyId" {
+		t.Fatalf("Invalid key %v", input.Key)
+	}
+	if aws.StringValue(input.ConditionExpression) != "attribute_exists (#0)" {
+		t.Fatalf("Invalid condition %s", aws.StringValue(input.ConditionExpression))
+	}
+
+	dynamoMock.WriteErr = awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "dummy failed", nil)
+	if _, err := dynamoDBClient.DeleteItem("test-table", NewKey("id", "dummyId"), IfExists("id")); err != ErrConditionFailed {
+		t.Fatalf("Failed condition must be ErrConditionFailed err=%v", err)
+	}
+}
+
+func TestUpdateItem(t *testing.T) {
+	dynamoMock := &DynamoMock{}
+	dynamoDBClient := New(dynamoMock)
+
+	update := expression.Set(expression.Name("desc_text"), expression.Value("updated"))
+	_, err := dynamoDBClient.UpdateItem("test-table", NewKey("id", "dummyId"), update,
+		WithCondition(expression.Name("desc_text").Equal(expression.Value("dummyText"))))
+	if err != nil {
+		t.Fatalf("Update item failure %s", err.Error())
+	}
+	input := dynamoMock.UpdateItemInput
+	if aws.StringValue(input.UpdateExpression) != "SET #0 = :1\n" {
+		t.Fatalf("Invalid update %q", aws.StringValue(input.UpdateExpression))
+	}
+	if aws.StringValue(input.ConditionExpression) != "#0 = :0" {
+		t.Fatalf("Condition must share the placeholders with the update %s", aws.StringValue(input.ConditionExpression))
+	}
+	if aws.StringValue(input.ExpressionAttributeValues[":0"].S) != "dummyText" {
+		t.Fatalf("Invalid values %v", input.ExpressionAttributeValues)
+	}
+	if input.ExpressionAttributeNames["#0"] == nil || *input.ExpressionAttributeNames["#0"] != "desc_text" {
+		t.Fatalf("Invalid names %v", input.ExpressionAttributeNames)
+	}
+}
@@ -0,0 +1,40 @@
+package dynamo
+
+import (
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/pkg/errors"
+)
+
+// ErrNotFound is returned when the item of the key does not exist
+var ErrNotFound = errors.New("Item not found")
+
+// ErrConditionFailed is returned when the condition of the write is not satisfied,
+// including the conflict of the optimistic lock by the version field
+var ErrConditionFailed = errors.New("Condition failed")
+
+// ErrTransactionConflict is the reason of the operation of a transaction cancelled by another
+// transaction or write on the same item
+var ErrTransactionConflict = errors.New("Transaction conflict")
+
+// ErrUnprocessed is the error of the items of a batch which are still unprocessed after the retries
+var ErrUnprocessed = errors.New("Item unprocessed")
+
+// isConditionFailed reports whether dynamodb rejected the write by its condition
+func isConditionFailed(err error) bool {
+	awsErr, ok := err.(awserr.Error)
+	return ok && awsErr.Code() == dynamodb.ErrCodeConditionalCheckFailedException
+}
+
+// isThrottled reports whether dynamodb rejected the request by the throughput or the request rate
+func isThrottled(err error) bool {
+	awsErr, ok := err.(awserr.Error)
+	if !ok {
+		return false
+	}
+	switch awsErr.Code() {
+	case dynamodb.ErrCodeProvisionedThroughputExceededException, dynamodb.ErrCodeRequestLimitExceeded, "ThrottlingException":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package dynamo
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/pkg/errors"
+)
+
+// itemSource fetches the pages of items one by one
+type itemSource interface {
+	nextPage(ctx context.Context) (items []map[string]*dynamodb.AttributeValue, last bool, err error)
+	close()
+}
+
+// ItemIterator iterates over the items of query or scan.
+// Pages are fetched lazily when the items of the previous page are consumed.
+//
+//	it := wrapper.QueryIterator(ctx, "table", expression.Key("user_id").Equal(expression.Value(42)))
+//	defer it.Close()
+//	for it.Next() {
+//		var record Record
+//		if err := it.Scan(&record); err != nil { ... }
+//	}
+//	if err := it.Err(); err != nil { ... }
+type ItemIterator struct {
+	ctx     context.Context
+	source  itemSource
+	limit   int64
+	items   []map[string]*dynamodb.AttributeValue
+	current map[string]*dynamodb.AttributeValue
+	last    bool
+	closed  bool
+	err     error
+	count   int64
+}
+
+func newItemIterator(ctx context.Context, source itemSource, limit int) *ItemIterator {
+	return &ItemIterator{
+		ctx:    ctx,
+		source: source,
+		limit:  int64(limit),
+	}
+}
+
+// failedItemIterator returns the iterator which yields no item and reports err
+func failedItemIterator(err error) *ItemIterator {
+	return &ItemIterator{err: err}
+}
+
+// Next advances the iterator to the next item. It returns false when the items are exhausted,
+// the limit is reached, the iterator is closed or an error occurred.
+func (it *ItemIterator) Next() bool {
+	it.current = nil
+	if it.closed || it.err != nil {
+		return false
+	}
+	if it.limit > 0 && it.count >= it.limit {
+		return false
+	}
+	for len(it.items) == 0 {
+		if it.last {
+			return false
+		}
+		if err := it.ctx.Err(); err != nil {
+			it.err = err
+			return false
+		}
+		items, last, err := it.source.nextPage(it.ctx)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.last = last
+		it.items = items
+	}
+	it.current = it.items[0]
+	it.items = it.items[1:]
+	it.count++
+	return true
+}
+
+// Item returns the attribute values of the current item
+func (it *ItemIterator) Item() map[string]*dynamodb.AttributeValue {
+	return it.current
+}
+
+// Scan unmarshals the current item into out, which must be a pointer
+func (it *ItemIterator) Scan(out interface{}) error {
+	if it.current == nil {
+		return errors.New("Scan called without current item")
+	}
+	if err := dynamodbattribute.UnmarshalMap(it.current, out); err != nil {
+		return errors.Wrap(err, "Unmarshal item failure")
+	}
+	return nil
+}
+
+// Count returns the number of items yielded so far
+func (it *ItemIterator) Count() int64 {
+	return it.count
+}
+
+// Err returns the error which stopped the iteration
+func (it *ItemIterator) Err() error {
+	return it.err
+}
+
+// Close stops the iteration and releases the buffered items
+func (it *ItemIterator) Close() error {
+	if it.closed {
+		return nil
+	}
+	it.closed = true
+	it.items = nil
+	it.current = nil
+	if it.source != nil {
+		it.source.close()
+	}
+	return nil
+}
+
+// collectItems unmarshals all the items of the iterator into out, which must be a pointer to a slice
+func collectItems(it *ItemIterator, out interface{}) error {
+	defer it.Close()
+	items := []map[string]*dynamodb.AttributeValue{}
+	for it.Next() {
+		items = append(items, it.Item())
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+	if err := dynamodbattribute.UnmarshalListOfMaps(items, out); err != nil {
+		return errors.Wrap(err, "Unmarshal items failure")
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package dynamo
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/pkg/errors"
+)
+
+// Key is the primary key of an item, which is a partition key and an optional sort key.
+// The values may be any scalar go value dynamodbattribute marshals into a string, number or binary.
+//
+//	key := dynamo.NewKey("user_id", 42).WithSort("created_at", "2020-01-01")
+type Key struct {
+	PartitionName  string
+	PartitionValue interface{}
+	SortName       string
+	SortValue      interface{}
+}
+
+// NewKey returns the key of the partition key only
+func NewKey(name string, value interface{}) Key {
+	return Key{PartitionName: name, PartitionValue: value}
+}
+
+// WithSort returns the key with the sort key
+func (k Key) WithSort(name string, value interface{}) Key {
+	k.SortName = name
+	k.SortValue = value
+	return k
+}
+
+func (k Key) attributeValues() (map[string]*dynamodb.AttributeValue, error) {
+	if k.PartitionName == "" {
+		return nil, errors.New("Key must have partition key name")
+	}
+	partition, err := marshalKeyValue(k.PartitionName, k.PartitionValue)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]*dynamodb.AttributeValue{k.PartitionName: partition}
+	if k.SortName == "" {
+		return values, nil
+	}
+	sort, err := marshalKeyValue(k.SortName, k.SortValue)
+	if err != nil {
+		return nil, err
+	}
+	values[k.SortName] = sort
+	return values, nil
+}
+
+// marshalKeyValue marshals the value of a key attribute, which must be a string, number or binary
+func marshalKeyValue(name string, value interface{}) (*dynamodb.AttributeValue, error) {
+	av, err := dynamodbattribute.Marshal(value)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("Marshal key failure name=%s", name))
+	}
+	if av.S == nil && av.N == nil && av.B == nil {
+		return nil, errors.New(fmt.Sprintf("Key must be string, number or binary name=%s value=%v", name, value))
+	}
+	return av, nil
+}
+
+// marshalKey returns the attribute values of the key accepted by GetItemWithKey
+func marshalKey(key interface{}) (map[string]*dynamodb.AttributeValue, error) {
+	switch k := key.(type) {
+	case Key:
+		return k.attributeValues()
+	case *Key:
+		return k.attributeValues()
+	case map[string]*dynamodb.AttributeValue:
+		return k, nil
+	}
+	values, err := dynamodbattribute.MarshalMap(key)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("Marshal key failure key=%+v", key))
+	}
+	if len(values) == 0 || len(values) > 2 {
+		return nil, errors.New(fmt.Sprintf("Key must have one or two attributes key=%+v", key))
+	}
+	return values, nil
+}
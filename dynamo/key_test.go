@@ -0,0 +1,68 @@
+package dynamo
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+type testKey struct {
+	UserID    int64  `dynamodbav:"user_id"`
+	CreatedAt string `dynamodbav:"created_at"`
+}
+
+func TestGetItemWithKey(t *testing.T) {
+	dynamoMock := &DynamoMock{}
+	dynamoDBClient := New(dynamoMock)
+
+	_, err := dynamoDBClient.GetItemWithKey("test-table", NewKey("user_id", 42).WithSort("created_at", "2020-01-01"))
+	if err != nil {
+		t.Fatalf("Get item failure %s", err.Error())
+	}
+	key := dynamoMock.GetItemInput.Key
+	if aws.StringValue(key["user_id"].N) != "42" || aws.StringValue(key["created_at"].S) != "2020-01-01" {
+		t.Fatalf("Invalid key %v", key)
+	}
+	if dynamoMock.GetItemInput.ConsistentRead != nil || dynamoMock.GetItemInput.ProjectionExpression != nil {
+		t.Fatalf("Options must not be set by default input=%v", dynamoMock.GetItemInput)
+	}
+
+	if _, err := dynamoDBClient.GetItemWithKey("test-table", NewKey("hash", []byte{0x01, 0x02})); err != nil {
+		t.Fatalf("Get item failure %s", err.Error())
+	}
+	if b := dynamoMock.GetItemInput.Key["hash"].B; len(b) != 2 || b[1] != 0x02 {
+		t.Fatalf("Binary key must be B %v", dynamoMock.GetItemInput.Key)
+	}
+
+	if _, err := dynamoDBClient.GetItemWithKey("test-table", testKey{UserID: 7, CreatedAt: "2020-01-02"}); err != nil {
+		t.Fatalf("Get item failure %s", err.Error())
+	}
+	key = dynamoMock.GetItemInput.Key
+	if aws.StringValue(key["user_id"].N) != "7" || aws.StringValue(key["created_at"].S) != "2020-01-02" {
+		t.Fatalf("Struct key must be marshaled %v", key)
+	}
+
+	raw := map[string]*dynamodb.AttributeValue{"id": {S: aws.String("dummyId")}}
+	if _, err := dynamoDBClient.GetItemWithKey("test-table", raw); err != nil {
+		t.Fatalf("Get item failure %s", err.Error())
+	}
+	if aws.StringValue(dynamoMock.GetItemInput.Key["id"].S) != "dummyId" {
+		t.Fatalf("Attribute values must be used as is %v", dynamoMock.GetItemInput.Key)
+	}
+}
+
+func TestInvalidKey(t *testing.T) {
+	dynamoDBClient := New(&DynamoMock{})
+	for _, key := range []interface{}{
+		NewKey("", "dummyId"),
+		NewKey("id", true),
+		NewKey("id", "dummyId").WithSort("tags", []string{"a"}),
+		map[string]string{},
+		map[string]string{"a": "1", "b": "2", "c": "3"},
+	} {
+		if _, err := dynamoDBClient.GetItemWithKey("test-table", key); err == nil {
+			t.Fatalf("Invalid key must be rejected key=%+v", key)
+		}
+	}
+}
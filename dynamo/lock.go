@@ -0,0 +1,78 @@
+package dynamo
+
+import (
+	"reflect"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+// versionLock is the optimistic lock of a record which has the version field
+type versionLock struct {
+	field   *taggedField
+	current int64
+}
+
+// newVersionLock returns the lock of the record, or nil when the record has no version field
+func newVersionLock(record interface{}) (*versionLock, error) {
+	v := reflect.ValueOf(record)
+	if !v.IsValid() {
+		return nil, nil
+	}
+	info, err := getStructInfo(v.Type())
+	if err != nil || info.version == nil {
+		return nil, err
+	}
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	field := v.FieldByIndex(info.version.index)
+	lock := &versionLock{field: info.version}
+	switch field.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		lock.current = int64(field.Uint())
+	default:
+		lock.current = field.Int()
+	}
+	return lock, nil
+}
+
+// condition returns the condition that the stored version is still the current one,
+// or that the item does not exist yet when the record is new
+func (l *versionLock) condition() expression.ConditionBuilder {
+	name := expression.Name(l.field.name)
+	if l.current == 0 {
+		return expression.AttributeNotExists(name)
+	}
+	return name.Equal(expression.Value(l.current))
+}
+
+// next returns the version written with the record
+func (l *versionLock) next() int64 {
+	return l.current + 1
+}
+
+// apply sets the next version into the item to write
+func (l *versionLock) apply(item map[string]*dynamodb.AttributeValue) {
+	item[l.field.name] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(l.next(), 10))}
+}
+
+// commit sets the next version into the record after the write succeeded, if the record is a pointer
+func (l *versionLock) commit(record interface{}) {
+	v := reflect.ValueOf(record)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return
+	}
+	field := v.Elem().FieldByIndex(l.field.index)
+	switch field.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		field.SetUint(uint64(l.next()))
+	default:
+		field.SetInt(l.next())
+	}
+}
@@ -0,0 +1,84 @@
+package dynamo
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+type versionedRecord struct {
+	ID      string `json:"id"`
+	Version int64  `json:"version" dynamo:"version"`
+}
+
+func TestPutItemCreateVersion(t *testing.T) {
+	dynamoMock := &DynamoMock{}
+	dynamoDBClient := New(dynamoMock)
+
+	record := &versionedRecord{ID: "dummyId"}
+	if _, err := dynamoDBClient.PutItem("test-table", record); err != nil {
+		t.Fatalf("Put item failure %s", err.Error())
+	}
+	input := dynamoMock.PutItemInput
+	if aws.StringValue(input.ConditionExpression) != "attribute_not_exists (#0)" {
+		t.Fatalf("New record must be create-only %s", aws.StringValue(input.ConditionExpression))
+	}
+	if aws.StringValue(input.Item["version"].N) != "1" || record.Version != 1 {
+		t.Fatalf("Version must be incremented item=%v record=%d", input.Item["version"], record.Version)
+	}
+}
+
+func TestPutItemUpdateVersion(t *testing.T) {
+	dynamoMock := &DynamoMock{}
+	dynamoDBClient := New(dynamoMock)
+
+	record := &versionedRecord{ID: "dummyId", Version: 3}
+	if _, err := dynamoDBClient.PutItem("test-table", record, IfExists("id")); err != nil {
+		t.Fatalf("Put item failure %s", err.Error())
+	}
+	input := dynamoMock.PutItemInput
+	if aws.StringValue(input.ConditionExpression) != "(attribute_exists (#0)) AND (#1 = :0)" {
+		t.Fatalf("Version must be combined with the condition %s", aws.StringValue(input.ConditionExpression))
+	}
+	if aws.StringValue(input.ExpressionAttributeValues[":0"].N) != "3" {
+		t.Fatalf("Stored version must be the current one %v", input.ExpressionAttributeValues)
+	}
+	if aws.StringValue(input.Item["version"].N) != "4" || record.Version != 4 {
+		t.Fatalf("Version must be incremented item=%v record=%d", input.Item["version"], record.Version)
+	}
+}
+
+func TestPutItemVersionConflict(t *testing.T) {
+	dynamoMock := &DynamoMock{
+		WriteErr: awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "dummy conflict", nil),
+	}
+	dynamoDBClient := New(dynamoMock)
+
+	record := &versionedRecord{ID: "dummyId", Version: 3}
+	if _, err := dynamoDBClient.PutItem("test-table", record); err != ErrConditionFailed {
+		t.Fatalf("Conflict must be ErrConditionFailed err=%v", err)
+	}
+	if record.Version != 3 {
+		t.Fatalf("Version must not change on conflict version=%d", record.Version)
+	}
+
+	dynamoMock.WriteErr = awserr.New(dynamodb.ErrCodeInternalServerError, "dummy error", nil)
+	if _, err := dynamoDBClient.PutItem("test-table", record); err == nil || err == ErrConditionFailed {
+		t.Fatalf("Other errors must not be ErrConditionFailed err=%v", err)
+	}
+}
+
+func TestPutItemVersionValue(t *testing.T) {
+	dynamoMock := &DynamoMock{}
+	dynamoDBClient := New(dynamoMock)
+
+	record := versionedRecord{ID: "dummyId", Version: 1}
+	if _, err := dynamoDBClient.PutItem("test-table", record); err != nil {
+		t.Fatalf("Put item failure %s", err.Error())
+	}
+	if aws.StringValue(dynamoMock.PutItemInput.Item["version"].N) != "2" || record.Version != 1 {
+		t.Fatalf("Record passed by value must be written with next version but not changed")
+	}
+}
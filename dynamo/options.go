@@ -0,0 +1,343 @@
+package dynamo
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/pkg/errors"
+)
+
+// ReadOption is an option of reading items
+type ReadOption func(*readConfig)
+
+type readConfig struct {
+	consistentRead bool
+	projection     []string
+	index          string
+	filter         *expression.ConditionBuilder
+	descending     bool
+	limit          int
+	pageSize       int
+	segments       int
+}
+
+// WithConsistentRead reads the item by strongly consistent read
+func WithConsistentRead() ReadOption {
+	return func(c *readConfig) {
+		c.consistentRead = true
+	}
+}
+
+// WithProjection reads only the attributes. Names are document paths like info.tags[0],
+// and are passed by placeholders so that reserved words like name can be used as is.
+func WithProjection(names ...string) ReadOption {
+	return func(c *readConfig) {
+		c.projection = append(c.projection, names...)
+	}
+}
+
+// WithIndex queries or scans the global or local secondary index instead of the table
+func WithIndex(name string) ReadOption {
+	return func(c *readConfig) {
+		c.index = name
+	}
+}
+
+// WithFilter drops the items which do not satisfy the condition from the results of query or scan.
+// The filtered items are still read and consume the capacity.
+func WithFilter(filter expression.ConditionBuilder) ReadOption {
+	return func(c *readConfig) {
+		c.filter = &filter
+	}
+}
+
+// WithDescending queries the items in the descending order of the sort key
+func WithDescending() ReadOption {
+	return func(c *readConfig) {
+		c.descending = true
+	}
+}
+
+// WithLimit stops query or scan after n items in total across the pages
+func WithLimit(n int) ReadOption {
+	return func(c *readConfig) {
+		c.limit = n
+	}
+}
+
+// WithPageSize sets the number of items evaluated by a request of query or scan
+func WithPageSize(n int) ReadOption {
+	return func(c *readConfig) {
+		c.pageSize = n
+	}
+}
+
+// WithSegments scans the table by n parallel segments. The order of the items is not kept.
+func WithSegments(n int) ReadOption {
+	return func(c *readConfig) {
+		c.segments = n
+	}
+}
+
+func newReadConfig(opts []ReadOption) readConfig {
+	conf := readConfig{}
+	for _, opt := range opts {
+		opt(&conf)
+	}
+	return conf
+}
+
+// checkOptions returns the error when an option other than the supported ones is set,
+// so that the options which the operation does not take are not silently ignored
+func (c readConfig) checkOptions(operation string, supported ...string) error {
+	options := []struct {
+		name string
+		set  bool
+	}{
+		{"WithConsistentRead", c.consistentRead},
+		{"WithProjection", len(c.projection) > 0},
+		{"WithIndex", c.index != ""},
+		{"WithFilter", c.filter != nil},
+		{"WithDescending", c.descending},
+		{"WithLimit", c.limit > 0},
+		{"WithPageSize", c.pageSize > 0},
+		{"WithSegments", c.segments > 0},
+	}
+	for _, option := range options {
+		if !option.set {
+			continue
+		}
+		isSupported := false
+		for _, name := range supported {
+			isSupported = isSupported || name == option.name
+		}
+		if !isSupported {
+			return errors.New(fmt.Sprintf("%s does not support %s", operation, option.name))
+		}
+	}
+	return nil
+}
+
+// expression builds the key condition, the filter and the projection of the config together,
+// so that they share the placeholders. It returns nil when there is nothing to build.
+func (c readConfig) expression(keyCondition *expression.KeyConditionBuilder) (*expression.Expression, error) {
+	builder := expression.NewBuilder()
+	empty := true
+	if keyCondition != nil {
+		builder = builder.WithKeyCondition(*keyCondition)
+		empty = false
+	}
+	if c.filter != nil {
+		builder = builder.WithFilter(*c.filter)
+		empty = false
+	}
+	if len(c.projection) > 0 {
+		names := make([]expression.NameBuilder, len(c.projection))
+		for i, name := range c.projection {
+			names[i] = expression.Name(name)
+		}
+		builder = builder.WithProjection(expression.NamesList(names[0], names[1:]...))
+		empty = false
+	}
+	if empty {
+		return nil, nil
+	}
+	expr, err := builder.Build()
+	if err != nil {
+		return nil, errors.Wrap(err, "Build expression failure")
+	}
+	return &expr, nil
+}
+
+// requestLimit returns the limit of items evaluated by a request
+func (c readConfig) requestLimit() *int64 {
+	switch {
+	case c.pageSize > 0:
+		return aws.Int64(int64(c.pageSize))
+	case c.limit > 0:
+		return aws.Int64(int64(c.limit))
+	}
+	return nil
+}
+
+func (c readConfig) getItemInput(tableName string, key map[string]*dynamodb.AttributeValue) (*dynamodb.GetItemInput, error) {
+	if err := c.checkOptions("GetItem", "WithConsistentRead", "WithProjection"); err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("Invalid read option table=%s", tableName))
+	}
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String(tableName),
+		Key:       key,
+	}
+	if c.consistentRead {
+		input.ConsistentRead = aws.Bool(true)
+	}
+	expr, err := c.expression(nil)
+	if err != nil {
+		return nil, err
+	}
+	if expr != nil {
+		input.ProjectionExpression = expr.Projection()
+		input.ExpressionAttributeNames = expr.Names()
+	}
+	return input, nil
+}
+
+func (c readConfig) queryInput(
+	tableName string, keyCondition expression.KeyConditionBuilder) (*dynamodb.QueryInput, error) {
+
+	err := c.checkOptions("Query", "WithIndex", "WithFilter", "WithProjection", "WithDescending",
+		"WithLimit", "WithPageSize", "WithConsistentRead")
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("Invalid read option table=%s", tableName))
+	}
+	expr, err := c.expression(&keyCondition)
+	if err != nil {
+		return nil, err
+	}
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String(tableName),
+		KeyConditionExpression:    expr.KeyCondition(),
+		FilterExpression:          expr.Filter(),
+		ProjectionExpression:      expr.Projection(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		Limit:                     c.requestLimit(),
+	}
+	if c.index != "" {
+		input.IndexName = aws.String(c.index)
+	}
+	if c.consistentRead {
+		input.ConsistentRead = aws.Bool(true)
+	}
+	if c.descending {
+		input.ScanIndexForward = aws.Bool(false)
+	}
+	return input, nil
+}
+
+func (c readConfig) scanInput(tableName string) (*dynamodb.ScanInput, error) {
+	err := c.checkOptions("Scan", "WithSegments", "WithIndex", "WithFilter", "WithProjection",
+		"WithLimit", "WithPageSize", "WithConsistentRead")
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("Invalid read option table=%s", tableName))
+	}
+	input := &dynamodb.ScanInput{
+		TableName: aws.String(tableName),
+		Limit:     c.requestLimit(),
+	}
+	expr, err := c.expression(nil)
+	if err != nil {
+		return nil, err
+	}
+	if expr != nil {
+		input.FilterExpression = expr.Filter()
+		input.ProjectionExpression = expr.Projection()
+		input.ExpressionAttributeNames = expr.Names()
+		input.ExpressionAttributeValues = expr.Values()
+	}
+	if c.index != "" {
+		input.IndexName = aws.String(c.index)
+	}
+	if c.consistentRead {
+		input.ConsistentRead = aws.Bool(true)
+	}
+	return input, nil
+}
+
+// WriteOption is an option of writing items
+type WriteOption func(*writeConfig)
+
+type writeConfig struct {
+	conditions   []expression.ConditionBuilder
+	returnValues string
+	out          interface{}
+}
+
+// WithCondition writes the item only when the condition is satisfied, otherwise the write
+// fails with ErrConditionFailed. Multiple conditions are combined by AND.
+//
+//	dynamo.WithCondition(expression.Name("status").Equal(expression.Value("pending")))
+func WithCondition(condition expression.ConditionBuilder) WriteOption {
+	return func(c *writeConfig) {
+		c.conditions = append(c.conditions, condition)
+	}
+}
+
+// IfNotExists writes the item only when no item has the key, which makes a put create-only.
+// name is the partition key name.
+func IfNotExists(name string) WriteOption {
+	return WithCondition(expression.AttributeNotExists(expression.Name(name)))
+}
+
+// IfExists writes the item only when the item of the key exists, which makes a put replace-only.
+// name is the partition key name.
+func IfExists(name string) WriteOption {
+	return WithCondition(expression.AttributeExists(expression.Name(name)))
+}
+
+// WithReturnValues returns the attributes of the item by the write and unmarshals them into out,
+// which must be a pointer. returnValues is one of dynamodb.ReturnValue*, e.g. ReturnValueAllNew
+// for the updated item or ReturnValueAllOld for the replaced or deleted one.
+// out is left as is when the write returns no attributes.
+func WithReturnValues(returnValues string, out interface{}) WriteOption {
+	return func(c *writeConfig) {
+		c.returnValues = returnValues
+		c.out = out
+	}
+}
+
+func newWriteConfig(opts []WriteOption) writeConfig {
+	conf := writeConfig{}
+	for _, opt := range opts {
+		opt(&conf)
+	}
+	return conf
+}
+
+func (c writeConfig) returnValuesInput() *string {
+	if c.returnValues == "" {
+		return nil
+	}
+	return aws.String(c.returnValues)
+}
+
+// unmarshalReturned unmarshals the attributes returned by the write into the destination of the config
+func (c writeConfig) unmarshalReturned(attributes map[string]*dynamodb.AttributeValue) error {
+	if c.out == nil || len(attributes) == 0 {
+		return nil
+	}
+	if err := dynamodbattribute.UnmarshalMap(attributes, c.out); err != nil {
+		return errors.Wrap(err, "Unmarshal returned attributes failure")
+	}
+	return nil
+}
+
+// expression builds the update and the conditions of the config together, so that they share
+// the placeholders. It returns nil when there is nothing to build.
+func (c writeConfig) expression(update *expression.UpdateBuilder) (*expression.Expression, error) {
+	builder := expression.NewBuilder()
+	empty := true
+	if update != nil {
+		builder = builder.WithUpdate(*update)
+		empty = false
+	}
+	if len(c.conditions) > 0 {
+		condition := c.conditions[0]
+		if len(c.conditions) > 1 {
+			condition = expression.And(c.conditions[0], c.conditions[1], c.conditions[2:]...)
+		}
+		builder = builder.WithCondition(condition)
+		empty = false
+	}
+	if empty {
+		return nil, nil
+	}
+	expr, err := builder.Build()
+	if err != nil {
+		return nil, errors.Wrap(err, "Build expression failure")
+	}
+	return &expr, nil
+}
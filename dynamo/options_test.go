@@ -0,0 +1,75 @@
+package dynamo
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+func TestReadOptions(t *testing.T) {
+	dynamoMock := &DynamoMock{}
+	dynamoDBClient := New(dynamoMock)
+
+	_, err := dynamoDBClient.GetItemWithKey("test-table", NewKey("id", "dummyId"),
+		WithConsistentRead(), WithProjection("name", "info.tags[0]"))
+	if err != nil {
+		t.Fatalf("Get item failure %s", err.Error())
+	}
+	input := dynamoMock.GetItemInput
+	if !aws.BoolValue(input.ConsistentRead) {
+		t.Fatalf("Consistent read must be set")
+	}
+	if aws.StringValue(input.ProjectionExpression) != "#0, #1.#2[0]" {
+		t.Fatalf("Invalid projection %s", aws.StringValue(input.ProjectionExpression))
+	}
+	names := map[string]string{}
+	for placeholder, name := range input.ExpressionAttributeNames {
+		names[placeholder] = aws.StringValue(name)
+	}
+	if names["#0"] != "name" || names["#1"] != "info" || names["#2"] != "tags" {
+		t.Fatalf("Invalid placeholder names %v", names)
+	}
+
+	if _, err := dynamoDBClient.GetItemWithKey("test-table", NewKey("id", "dummyId"), WithProjection("")); err == nil {
+		t.Fatalf("Invalid projection must be error")
+	}
+
+	dynamoMock.GetItemInput = nil
+	for _, opt := range []ReadOption{
+		WithIndex("by-email"),
+		WithFilter(expression.Name("name").Equal(expression.Value("foo"))),
+		WithDescending(),
+		WithLimit(1),
+		WithPageSize(1),
+		WithSegments(2),
+	} {
+		if _, err := dynamoDBClient.GetItemWithKey("test-table", NewKey("id", "dummyId"), opt); err == nil {
+			t.Fatalf("Query and scan option must be error")
+		}
+	}
+	if dynamoMock.GetItemInput != nil {
+		t.Fatalf("Item must not be read with unsupported option")
+	}
+}
+
+func TestWriteOptions(t *testing.T) {
+	dynamoMock := &DynamoMock{}
+	dynamoDBClient := New(dynamoMock)
+
+	if _, err := dynamoDBClient.PutItem("test-table", TestRecord{ID: "dummyId"}); err != nil {
+		t.Fatalf("Put item failure %s", err.Error())
+	}
+	if dynamoMock.PutItemInput.ConditionExpression != nil || dynamoMock.PutItemInput.ExpressionAttributeNames != nil {
+		t.Fatalf("Put without options must be unconditional")
+	}
+
+	if _, err := dynamoDBClient.PutItem("test-table", TestRecord{ID: "dummyId"}, IfNotExists("id")); err != nil {
+		t.Fatalf("Put item failure %s", err.Error())
+	}
+	input := dynamoMock.PutItemInput
+	if aws.StringValue(input.ConditionExpression) != "attribute_not_exists (#0)" ||
+		aws.StringValue(input.ExpressionAttributeNames["#0"]) != "id" {
+		t.Fatalf("Invalid condition %s names=%v", aws.StringValue(input.ConditionExpression), input.ExpressionAttributeNames)
+	}
+}
@@ -0,0 +1,54 @@
+package dynamo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/pkg/errors"
+)
+
+// Query unmarshals all the items which satisfy the key condition into out, which must be
+// a pointer to a slice, paging through the results automatically.
+// The key condition is built by the expression package like
+//
+//	expression.Key("user_id").Equal(expression.Value(42)).
+//		And(expression.Key("created_at").BeginsWith("2020-01"))
+//
+// WithIndex, WithFilter, WithProjection, WithDescending, WithLimit, WithPageSize and
+// WithConsistentRead are available as opts.
+func (s *wrapperDynamo) Query(ctx context.Context,
+	tableName string, keyCondition expression.KeyConditionBuilder, out interface{}, opts ...ReadOption) error {
+
+	return collectItems(s.QueryIterator(ctx, tableName, keyCondition, opts...), out)
+}
+
+// QueryIterator returns the iterator over the items which satisfy the key condition. See Query about opts.
+func (s *wrapperDynamo) QueryIterator(ctx context.Context,
+	tableName string, keyCondition expression.KeyConditionBuilder, opts ...ReadOption) *ItemIterator {
+
+	conf := newReadConfig(opts)
+	input, err := conf.queryInput(tableName, keyCondition)
+	if err != nil {
+		return failedItemIterator(err)
+	}
+	return newItemIterator(ctx, &queryItemSource{client: s.Client, input: input}, conf.limit)
+}
+
+// queryItemSource fetches pages by Query
+type queryItemSource struct {
+	client AWSDynamo
+	input  *dynamodb.QueryInput
+}
+
+func (p *queryItemSource) nextPage(ctx context.Context) ([]map[string]*dynamodb.AttributeValue, bool, error) {
+	output, err := p.client.Query(p.input)
+	if err != nil {
+		return nil, false, errors.Wrap(err, fmt.Sprintf("Query failure table=%s", *p.input.TableName))
+	}
+	p.input.ExclusiveStartKey = output.LastEvaluatedKey
+	return output.Items, len(output.LastEvaluatedKey) == 0, nil
+}
+
+func (p *queryItemSource) close() {}
@@ -0,0 +1,118 @@
+package dynamo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+func TestQuery(t *testing.T) {
+	dynamoMock := &DynamoMock{Items: makeItems(5)}
+	dynamoDBClient := New(dynamoMock)
+
+	keyCondition := expression.Key("id").Equal(expression.Value("dummyId")).
+		And(expression.Key("created_at").Between(expression.Value("2020-01-01"), expression.Value("2020-12-31")))
+	records := []TestRecord{}
+	err := dynamoDBClient.Query(context.Background(), "test-table", keyCondition, &records,
+		WithIndex("by-date"), WithDescending(), WithConsistentRead(),
+		WithFilter(expression.Name("name").BeginsWith("a")))
+	if err != nil {
+		t.Fatalf("Query failure %s", err.Error())
+	}
+	if len(records) != 5 || records[0].ID != "id0" || records[4].DescText != "text4" {
+		t.Fatalf("Invalid records %+v", records)
+	}
+	if len(dynamoMock.QueryInputs) != 3 {
+		t.Fatalf("Query must page through the results requests=%d", len(dynamoMock.QueryInputs))
+	}
+	input := dynamoMock.QueryInputs[0]
+	if aws.StringValue(input.KeyConditionExpression) != "(#1 = :1) AND (#2 BETWEEN :2 AND :3)" {
+		t.Fatalf("Invalid key condition %s", aws.StringValue(input.KeyConditionExpression))
+	}
+	if aws.StringValue(input.FilterExpression) != "begins_with (#0, :0)" {
+		t.Fatalf("Filter must share the placeholders %s", aws.StringValue(input.FilterExpression))
+	}
+	if aws.StringValue(input.IndexName) != "by-date" || aws.BoolValue(input.ScanIndexForward) ||
+		!aws.BoolValue(input.ConsistentRead) {
+		t.Fatalf("Invalid query options %v", input)
+	}
+	if dynamoMock.QueryInputs[2].ExclusiveStartKey == nil {
+		t.Fatalf("Next page must start from the last evaluated key")
+	}
+}
+
+func TestQueryLimit(t *testing.T) {
+	dynamoMock := &DynamoMock{Items: makeItems(10)}
+	dynamoDBClient := New(dynamoMock)
+
+	keyCondition := expression.Key("id").Equal(expression.Value("dummyId"))
+	records := []TestRecord{}
+	err := dynamoDBClient.Query(context.Background(), "test-table", keyCondition, &records,
+		WithLimit(3), WithPageSize(2))
+	if err != nil {
+		t.Fatalf("Query failure %s", err.Error())
+	}
+	if len(records) != 3 {
+		t.Fatalf("Query must stop at the limit records=%d", len(records))
+	}
+	if len(dynamoMock.QueryInputs) != 2 || aws.Int64Value(dynamoMock.QueryInputs[0].Limit) != 2 {
+		t.Fatalf("Query must not read pages after the limit requests=%d", len(dynamoMock.QueryInputs))
+	}
+}
+
+func TestQueryIterator(t *testing.T) {
+	dynamoMock := &DynamoMock{Items: makeItems(5)}
+	dynamoDBClient := New(dynamoMock)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	it := dynamoDBClient.QueryIterator(ctx, "test-table", expression.Key("id").Equal(expression.Value("dummyId")))
+	defer it.Close()
+	var record TestRecord
+	for it.Next() {
+		if err := it.Scan(&record); err != nil {
+			t.Fatalf("Scan failure %s", err.Error())
+		}
+		if it.Count() == 2 {
+			cancel()
+		}
+	}
+	if it.Err() != context.Canceled || it.Count() != 2 || record.ID != "id1" {
+		t.Fatalf("Cancelled iterator must stop at the page count=%d err=%v", it.Count(), it.Err())
+	}
+}
+
+func TestQueryError(t *testing.T) {
+	dynamoMock := &DynamoMock{
+		Items:   makeItems(1),
+		ReadErr: awserr.New(dynamodb.ErrCodeResourceNotFoundException, "dummy not found", nil),
+	}
+	dynamoDBClient := New(dynamoMock)
+
+	records := []TestRecord{}
+	keyCondition := expression.Key("id").Equal(expression.Value("dummyId"))
+	if err := dynamoDBClient.Query(context.Background(), "test-table", keyCondition, &records); err == nil {
+		t.Fatalf("Query error must be returned")
+	}
+	if err := dynamoDBClient.Query(context.Background(), "test-table", expression.KeyConditionBuilder{}, &records); err == nil {
+		t.Fatalf("Invalid key condition must be error")
+	}
+}
+
+func TestQueryInvalidOptions(t *testing.T) {
+	dynamoMock := &DynamoMock{Items: makeItems(1)}
+	dynamoDBClient := New(dynamoMock)
+
+	records := []TestRecord{}
+	keyCondition := expression.Key("id").Equal(expression.Value("dummyId"))
+	if err := dynamoDBClient.Query(context.Background(), "test-table", keyCondition, &records, WithSegments(2)); err == nil {
+		t.Fatalf("WithSegments must be error")
+	}
+	if len(dynamoMock.QueryInputs) != 0 {
+		t.Fatalf("Items must not be queried with unsupported option")
+	}
+}
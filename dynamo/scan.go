@@ -0,0 +1,121 @@
+package dynamo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/pkg/errors"
+)
+
+// Scan unmarshals all the items of the table into out, which must be a pointer to a slice,
+// paging through the results automatically. WithSegments scans the segments in parallel.
+// WithIndex, WithFilter, WithProjection, WithLimit, WithPageSize and WithConsistentRead are also available.
+func (s *wrapperDynamo) Scan(ctx context.Context, tableName string, out interface{}, opts ...ReadOption) error {
+	return collectItems(s.ScanIterator(ctx, tableName, opts...), out)
+}
+
+// ScanIterator returns the iterator over the items of the table. See Scan about opts.
+func (s *wrapperDynamo) ScanIterator(ctx context.Context, tableName string, opts ...ReadOption) *ItemIterator {
+	conf := newReadConfig(opts)
+	input, err := conf.scanInput(tableName)
+	if err != nil {
+		return failedItemIterator(err)
+	}
+	if conf.segments <= 1 {
+		return newItemIterator(ctx, &scanItemSource{client: s.Client, input: input}, conf.limit)
+	}
+	return newItemIterator(ctx, newParallelScanSource(ctx, s.Client, input, conf.segments), conf.limit)
+}
+
+// scanItemSource fetches pages of a segment by Scan
+type scanItemSource struct {
+	client AWSDynamo
+	input  *dynamodb.ScanInput
+}
+
+func (p *scanItemSource) nextPage(ctx context.Context) ([]map[string]*dynamodb.AttributeValue, bool, error) {
+	output, err := p.client.Scan(p.input)
+	if err != nil {
+		return nil, false, errors.Wrap(err, fmt.Sprintf(
+			"Scan failure table=%s segment=%d", *p.input.TableName, aws.Int64Value(p.input.Segment)))
+	}
+	p.input.ExclusiveStartKey = output.LastEvaluatedKey
+	return output.Items, len(output.LastEvaluatedKey) == 0, nil
+}
+
+func (p *scanItemSource) close() {}
+
+// scanPage is a page of a segment fetched in background
+type scanPage struct {
+	items []map[string]*dynamodb.AttributeValue
+	err   error
+}
+
+// parallelScanSource scans the segments in parallel and yields the pages in the order they arrive
+type parallelScanSource struct {
+	cancel context.CancelFunc
+	pages  chan scanPage
+}
+
+func newParallelScanSource(
+	ctx context.Context, client AWSDynamo, input *dynamodb.ScanInput, segments int) *parallelScanSource {
+
+	ctx, cancel := context.WithCancel(ctx)
+	p := &parallelScanSource{
+		cancel: cancel,
+		pages:  make(chan scanPage, segments),
+	}
+	wg := sync.WaitGroup{}
+	for segment := 0; segment < segments; segment++ {
+		segmentInput := *input
+		segmentInput.Segment = aws.Int64(int64(segment))
+		segmentInput.TotalSegments = aws.Int64(int64(segments))
+		source := &scanItemSource{client: client, input: &segmentInput}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				if ctx.Err() != nil {
+					return
+				}
+				items, last, err := source.nextPage(ctx)
+				select {
+				case p.pages <- scanPage{items: items, err: err}:
+				case <-ctx.Done():
+					return
+				}
+				if last || err != nil {
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(p.pages)
+	}()
+	return p
+}
+
+func (p *parallelScanSource) nextPage(ctx context.Context) ([]map[string]*dynamodb.AttributeValue, bool, error) {
+	select {
+	case page, ok := <-p.pages:
+		if !ok {
+			return nil, true, nil
+		}
+		if page.err != nil {
+			p.cancel()
+			return nil, false, page.err
+		}
+		return page.items, false, nil
+	case <-ctx.Done():
+		return nil, false, ctx.Err()
+	}
+}
+
+func (p *parallelScanSource) close() {
+	p.cancel()
+}
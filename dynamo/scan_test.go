@@ -0,0 +1,108 @@
+package dynamo
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+func TestScan(t *testing.T) {
+	dynamoMock := &DynamoMock{Items: makeItems(3)}
+	dynamoDBClient := New(dynamoMock)
+
+	records := []TestRecord{}
+	err := dynamoDBClient.Scan(context.Background(), "test-table", &records,
+		WithFilter(expression.Name("desc_text").Contains("text")), WithProjection("id", "desc_text"))
+	if err != nil {
+		t.Fatalf("Scan failure %s", err.Error())
+	}
+	if len(records) != 3 || records[2].ID != "id2" {
+		t.Fatalf("Invalid records %+v", records)
+	}
+	input := dynamoMock.ScanInputs[0]
+	if aws.StringValue(input.FilterExpression) != "contains (#0, :0)" || aws.StringValue(input.ProjectionExpression) != "#1, #0" {
+		t.Fatalf("Invalid scan expressions filter=%s projection=%s",
+			aws.StringValue(input.FilterExpression), aws.StringValue(input.ProjectionExpression))
+	}
+	if input.Segment != nil {
+		t.Fatalf("Scan without segments must not be parallel")
+	}
+}
+
+func TestParallelScan(t *testing.T) {
+	dynamoMock := &DynamoMock{Items: makeItems(11)}
+	dynamoDBClient := New(dynamoMock)
+
+	records := []TestRecord{}
+	if err := dynamoDBClient.Scan(context.Background(), "test-table", &records, WithSegments(3)); err != nil {
+		t.Fatalf("Scan failure %s", err.Error())
+	}
+	if len(records) != 11 {
+		t.Fatalf("Parallel scan must read every segment records=%d", len(records))
+	}
+	ids := []string{}
+	for _, record := range records {
+		ids = append(ids, record.ID)
+	}
+	sort.Strings(ids)
+	for i := 1; i < len(ids); i++ {
+		if ids[i] == ids[i-1] {
+			t.Fatalf("Duplicated item %s", ids[i])
+		}
+	}
+	segments := map[int64]bool{}
+	for _, input := range dynamoMock.ScanInputs {
+		if aws.Int64Value(input.TotalSegments) != 3 {
+			t.Fatalf("Invalid total segments %d", aws.Int64Value(input.TotalSegments))
+		}
+		segments[aws.Int64Value(input.Segment)] = true
+	}
+	if len(segments) != 3 {
+		t.Fatalf("Every segment must be scanned segments=%v", segments)
+	}
+}
+
+func TestParallelScanError(t *testing.T) {
+	dynamoMock := &DynamoMock{
+		Items:   makeItems(4),
+		ReadErr: awserr.New(dynamodb.ErrCodeProvisionedThroughputExceededException, "dummy throttle", nil),
+	}
+	dynamoDBClient := New(dynamoMock)
+
+	it := dynamoDBClient.ScanIterator(context.Background(), "test-table", WithSegments(2))
+	defer it.Close()
+	if it.Next() || it.Err() == nil {
+		t.Fatalf("Scan error must stop the iterator")
+	}
+}
+
+func TestParallelScanLimit(t *testing.T) {
+	dynamoMock := &DynamoMock{Items: makeItems(20)}
+	dynamoDBClient := New(dynamoMock)
+
+	it := dynamoDBClient.ScanIterator(context.Background(), "test-table", WithSegments(4), WithLimit(5))
+	for it.Next() {
+	}
+	it.Close()
+	if it.Err() != nil || it.Count() != 5 {
+		t.Fatalf("Parallel scan must stop at the limit count=%d err=%v", it.Count(), it.Err())
+	}
+}
+
+func TestScanInvalidOptions(t *testing.T) {
+	dynamoMock := &DynamoMock{Items: makeItems(1)}
+	dynamoDBClient := New(dynamoMock)
+
+	records := []TestRecord{}
+	if err := dynamoDBClient.Scan(context.Background(), "test-table", &records, WithDescending()); err == nil {
+		t.Fatalf("WithDescending must be error")
+	}
+	if len(dynamoMock.ScanInputs) != 0 {
+		t.Fatalf("Items must not be scanned with unsupported option")
+	}
+}
@@ -0,0 +1,317 @@
+package dynamo
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/pkg/errors"
+)
+
+// now returns the current time, which the tests replace
+var now = time.Now
+
+// Table is a table bound to the struct type of its records, whose keys are declared by the dynamo tags.
+// pk and sk are the keys of the table, pk=index and sk=index are the keys of a secondary index,
+// ttl is the expiration time in epoch seconds, and version is the optimistic lock as PutItem does.
+//
+//	type User struct {
+//		ID        string `dynamodbav:"id" dynamo:"pk"`
+//		Email     string `dynamodbav:"email" dynamo:"pk=by-email"`
+//		CreatedAt string `dynamodbav:"created_at" dynamo:"sk=by-email"`
+//		ExpiresAt int64  `dynamodbav:"expires_at,omitempty" dynamo:"ttl"`
+//		Version   int64  `dynamodbav:"version" dynamo:"version"`
+//	}
+//
+//	users, err := dynamo.NewTable(wrapper, "users", User{})
+//	user := &User{ID: "42"}
+//	err = users.Get(ctx, user)
+type Table struct {
+	wrapper WrapperDynamo
+	name    string
+	typ     reflect.Type
+	info    *structInfo
+}
+
+// NewTable returns the table of the records of the type of model, which must be a struct with the pk field
+func NewTable(wrapper WrapperDynamo, tableName string, model interface{}) (*Table, error) {
+	typ := reflect.TypeOf(model)
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil, errors.New(fmt.Sprintf("Model must be struct model=%T", model))
+	}
+	info, err := getStructInfo(typ)
+	if err != nil {
+		return nil, err
+	}
+	if info.partition == nil {
+		return nil, errors.New(fmt.Sprintf("Model must have the field tagged `%s:\"%s\"` type=%s", tagName, tagPartition, typ))
+	}
+	return &Table{wrapper: wrapper, name: tableName, typ: typ, info: info}, nil
+}
+
+// Name returns the name of the table
+func (t *Table) Name() string {
+	return t.name
+}
+
+// Key returns the primary key of the record by its key fields
+func (t *Table) Key(record interface{}) (Key, error) {
+	v, err := t.value(record)
+	if err != nil {
+		return Key{}, err
+	}
+	key := NewKey(t.info.partition.name, v.FieldByIndex(t.info.partition.index).Interface())
+	if t.info.sort != nil {
+		key = key.WithSort(t.info.sort.name, v.FieldByIndex(t.info.sort.index).Interface())
+	}
+	return key, nil
+}
+
+// Get reads the item of the key fields of the record into the record, which must be a pointer.
+// It returns ErrNotFound when the item does not exist or is expired by the ttl field.
+func (t *Table) Get(ctx context.Context, record interface{}, opts ...ReadOption) error {
+	if err := t.checkPointer(record); err != nil {
+		return err
+	}
+	key, err := t.Key(record)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	output, err := t.wrapper.GetItemWithKey(t.name, key, opts...)
+	if err != nil {
+		return err
+	}
+	if len(output.Item) == 0 || t.expired(output.Item) {
+		return ErrNotFound
+	}
+	if err := dynamodbattribute.UnmarshalMap(output.Item, record); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("Unmarshal item failure table=%s", t.name))
+	}
+	return nil
+}
+
+// Put puts the record. See PutItem about the version field and opts.
+func (t *Table) Put(ctx context.Context, record interface{}, opts ...WriteOption) error {
+	if _, err := t.value(record); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	_, err := t.wrapper.PutItem(t.name, record, opts...)
+	return err
+}
+
+// Update updates the item of the key fields of the record by the update, and replaces the record,
+// which must be a pointer, by the updated item. The item is created when it does not exist, unless
+// IfExists is in opts. With the version field, the update is locked and increments the version.
+// WithReturnValues is not available as opts since the record receives the updated item.
+func (t *Table) Update(ctx context.Context, record interface{}, update *Update, opts ...WriteOption) error {
+	if err := t.checkPointer(record); err != nil {
+		return err
+	}
+	v, err := t.value(record)
+	if err != nil {
+		return err
+	}
+	key, err := t.Key(record)
+	if err != nil {
+		return err
+	}
+	if newWriteConfig(opts).returnValues != "" {
+		return errors.New(fmt.Sprintf("Update of table does not support WithReturnValues table=%s", t.name))
+	}
+	builder, err := update.build()
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("Build update failure table=%s", t.name))
+	}
+	lock, err := newVersionLock(record)
+	if err != nil {
+		return err
+	}
+	// the updated item is unmarshalled into a new record, so that the removed attributes are not left
+	updated := reflect.New(t.typ)
+	opts = append(opts[:len(opts):len(opts)], WithReturnValues(dynamodb.ReturnValueAllNew, updated.Interface()))
+	if lock != nil {
+		builder = builder.Set(expression.Name(lock.field.name), expression.Value(lock.next()))
+		opts = append(opts, WithCondition(lock.condition()))
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	output, err := t.wrapper.UpdateItem(t.name, key, builder, opts...)
+	if err != nil {
+		return err
+	}
+	if len(output.Attributes) > 0 {
+		v.Set(updated.Elem())
+	}
+	return nil
+}
+
+// Delete deletes the item of the key fields of the record. With the version field which is not zero,
+// the delete succeeds only when the stored version equals the field, otherwise ErrConditionFailed.
+func (t *Table) Delete(ctx context.Context, record interface{}, opts ...WriteOption) error {
+	key, err := t.Key(record)
+	if err != nil {
+		return err
+	}
+	lock, err := newVersionLock(record)
+	if err != nil {
+		return err
+	}
+	if lock != nil && lock.current != 0 {
+		opts = append(opts[:len(opts):len(opts)], WithCondition(lock.condition()))
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	_, err = t.wrapper.DeleteItem(t.name, key, opts...)
+	return err
+}
+
+// Query unmarshals the items of the partition key value into out, which must be a pointer to a slice.
+// sort is the condition of the sort key, or nil for all the items of the partition.
+// WithIndex queries the index by its keys declared by the tags. The expired items are skipped.
+// See WrapperDynamo.Query about opts.
+func (t *Table) Query(ctx context.Context, partition interface{}, sort SortCondition, out interface{}, opts ...ReadOption) error {
+	return collectItems(t.QueryIterator(ctx, partition, sort, opts...), out)
+}
+
+// QueryIterator returns the iterator over the items of the partition key value. See Query about the arguments.
+func (t *Table) QueryIterator(ctx context.Context, partition interface{}, sort SortCondition, opts ...ReadOption) *ItemIterator {
+	partitionField, sortField := t.info.partition, t.info.sort
+	if index := newReadConfig(opts).index; index != "" {
+		keys, ok := t.info.indexes[index]
+		if !ok {
+			return failedItemIterator(errors.New(fmt.Sprintf("Unknown index table=%s index=%s", t.name, index)))
+		}
+		if keys.partition != nil {
+			partitionField = keys.partition
+		}
+		sortField = keys.sort
+	}
+	keyCondition := expression.Key(partitionField.name).Equal(expression.Value(partition))
+	if sort != nil {
+		if sortField == nil {
+			return failedItemIterator(errors.New(fmt.Sprintf("Sort condition without sort key table=%s", t.name)))
+		}
+		keyCondition = keyCondition.And(sort(expression.Key(sortField.name)))
+	}
+	if t.info.ttl != nil {
+		opts = append(opts[:len(opts):len(opts)], t.withoutExpired())
+	}
+	return t.wrapper.QueryIterator(ctx, t.name, keyCondition, opts...)
+}
+
+// withoutExpired adds the filter of the items which are not expired to the filter of the options
+func (t *Table) withoutExpired() ReadOption {
+	name := expression.Name(t.info.ttl.name)
+	notExpired := expression.Or(
+		expression.AttributeNotExists(name),
+		name.Equal(expression.Value(0)),
+		name.GreaterThan(expression.Value(now().Unix())),
+	)
+	return func(c *readConfig) {
+		filter := notExpired
+		if c.filter != nil {
+			filter = c.filter.And(notExpired)
+		}
+		c.filter = &filter
+	}
+}
+
+// expired reports whether the ttl of the item has passed. Zero ttl never expires.
+func (t *Table) expired(item map[string]*dynamodb.AttributeValue) bool {
+	if t.info.ttl == nil || item[t.info.ttl.name] == nil {
+		return false
+	}
+	ttl, err := strconv.ParseInt(aws.StringValue(item[t.info.ttl.name].N), 10, 64)
+	return err == nil && ttl > 0 && ttl <= now().Unix()
+}
+
+// value returns the struct of the record, which must be the model type or a pointer to it
+func (t *Table) value(record interface{}) (reflect.Value, error) {
+	v := reflect.ValueOf(record)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}, errors.New(fmt.Sprintf("Record must not be nil table=%s", t.name))
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() || v.Type() != t.typ {
+		return reflect.Value{}, errors.New(fmt.Sprintf("Record must be %s table=%s record=%T", t.typ, t.name, record))
+	}
+	return v, nil
+}
+
+func (t *Table) checkPointer(record interface{}) error {
+	if reflect.ValueOf(record).Kind() != reflect.Ptr {
+		return errors.New(fmt.Sprintf("Record must be pointer table=%s record=%T", t.name, record))
+	}
+	return nil
+}
+
+// SortCondition is the condition of the sort key of Table.Query
+type SortCondition func(key expression.KeyBuilder) expression.KeyConditionBuilder
+
+// SortEqual matches the sort key equal to the value
+func SortEqual(value interface{}) SortCondition {
+	return func(key expression.KeyBuilder) expression.KeyConditionBuilder {
+		return key.Equal(expression.Value(value))
+	}
+}
+
+// SortLessThan matches the sort key less than the value
+func SortLessThan(value interface{}) SortCondition {
+	return func(key expression.KeyBuilder) expression.KeyConditionBuilder {
+		return key.LessThan(expression.Value(value))
+	}
+}
+
+// SortLessThanEqual matches the sort key less than or equal to the value
+func SortLessThanEqual(value interface{}) SortCondition {
+	return func(key expression.KeyBuilder) expression.KeyConditionBuilder {
+		return key.LessThanEqual(expression.Value(value))
+	}
+}
+
+// SortGreaterThan matches the sort key greater than the value
+func SortGreaterThan(value interface{}) SortCondition {
+	return func(key expression.KeyBuilder) expression.KeyConditionBuilder {
+		return key.GreaterThan(expression.Value(value))
+	}
+}
+
+// SortGreaterThanEqual matches the sort key greater than or equal to the value
+func SortGreaterThanEqual(value interface{}) SortCondition {
+	return func(key expression.KeyBuilder) expression.KeyConditionBuilder {
+		return key.GreaterThanEqual(expression.Value(value))
+	}
+}
+
+// SortBetween matches the sort key between lower and upper inclusive
+func SortBetween(lower interface{}, upper interface{}) SortCondition {
+	return func(key expression.KeyBuilder) expression.KeyConditionBuilder {
+		return key.Between(expression.Value(lower), expression.Value(upper))
+	}
+}
+
+// SortBeginsWith matches the string sort key which begins with the prefix
+func SortBeginsWith(prefix string) SortCondition {
+	return func(key expression.KeyBuilder) expression.KeyConditionBuilder {
+		return key.BeginsWith(prefix)
+	}
+}
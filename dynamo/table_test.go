@@ -0,0 +1,220 @@
+package dynamo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+type tableRecord struct {
+	ID        string `dynamodbav:"id" dynamo:"pk"`
+	CreatedAt string `dynamodbav:"created_at" dynamo:"sk,sk=by-date"`
+	Email     string `dynamodbav:"email" dynamo:"pk=by-email"`
+	Name      string `dynamodbav:"name"`
+	ExpiresAt int64  `dynamodbav:"expires_at,omitempty" dynamo:"ttl"`
+	Version   int64  `dynamodbav:"version" dynamo:"version"`
+}
+
+func fixNow(t *testing.T, unix int64) {
+	now = func() time.Time { return time.Unix(unix, 0) }
+	t.Cleanup(func() { now = time.Now })
+}
+
+func TestNewTable(t *testing.T) {
+	dynamoDBClient := New(&DynamoMock{})
+	if _, err := NewTable(dynamoDBClient, "records", &tableRecord{}); err != nil {
+		t.Fatalf("New table failure %s", err.Error())
+	}
+	if _, err := NewTable(dynamoDBClient, "records", TestRecord{}); err == nil {
+		t.Fatalf("Model without pk must be error")
+	}
+	if _, err := NewTable(dynamoDBClient, "records", "dummy"); err == nil {
+		t.Fatalf("Non struct model must be error")
+	}
+}
+
+func TestTableGet(t *testing.T) {
+	fixNow(t, 1000)
+	dynamoMock := &DynamoMock{Item: map[string]*dynamodb.AttributeValue{
+		"id":         {S: aws.String("dummyId")},
+		"created_at": {S: aws.String("2020-01-01")},
+		"name":       {S: aws.String("dummyName")},
+		"expires_at": {N: aws.String("2000")},
+	}}
+	table, err := NewTable(New(dynamoMock), "records", tableRecord{})
+	if err != nil {
+		t.Fatalf("New table failure %s", err.Error())
+	}
+
+	record := &tableRecord{ID: "dummyId", CreatedAt: "2020-01-01"}
+	if err := table.Get(context.Background(), record, WithConsistentRead()); err != nil {
+		t.Fatalf("Get failure %s", err.Error())
+	}
+	if record.Name != "dummyName" {
+		t.Fatalf("Invalid record %+v", record)
+	}
+	key := dynamoMock.GetItemInput.Key
+	if aws.StringValue(key["id"].S) != "dummyId" || aws.StringValue(key["created_at"].S) != "2020-01-01" || len(key) != 2 {
+		t.Fatalf("Key must be inferred from the record %v", key)
+	}
+	if aws.StringValue(dynamoMock.GetItemInput.TableName) != "records" {
+		t.Fatalf("Invalid table %s", aws.StringValue(dynamoMock.GetItemInput.TableName))
+	}
+
+	fixNow(t, 2000)
+	if err := table.Get(context.Background(), record); err != ErrNotFound {
+		t.Fatalf("Expired item must be ErrNotFound err=%v", err)
+	}
+	if err := table.Get(context.Background(), *record); err == nil {
+		t.Fatalf("Non pointer record must be error")
+	}
+	if err := table.Get(context.Background(), &TestRecord{}); err == nil {
+		t.Fatalf("Record of another type must be error")
+	}
+}
+
+func TestTablePut(t *testing.T) {
+	dynamoMock := &DynamoMock{}
+	table, _ := NewTable(New(dynamoMock), "records", tableRecord{})
+
+	record := &tableRecord{ID: "dummyId", CreatedAt: "2020-01-01", Version: 1}
+	if err := table.Put(context.Background(), record); err != nil {
+		t.Fatalf("Put failure %s", err.Error())
+	}
+	input := dynamoMock.PutItemInput
+	if aws.StringValue(input.TableName) != "records" || aws.StringValue(input.ConditionExpression) != "#0 = :0" {
+		t.Fatalf("Put must be locked by the version %v", input)
+	}
+	if record.Version != 2 {
+		t.Fatalf("Version must be incremented version=%d", record.Version)
+	}
+}
+
+func TestTableUpdate(t *testing.T) {
+	dynamoMock := &DynamoMock{ReturnAttributes: map[string]*dynamodb.AttributeValue{
+		"id":         {S: aws.String("dummyId")},
+		"created_at": {S: aws.String("2020-01-01")},
+		"name":       {S: aws.String("updated")},
+		"version":    {N: aws.String("4")},
+	}}
+	table, _ := NewTable(New(dynamoMock), "records", tableRecord{})
+
+	update := NewUpdate().Set("name", "updated")
+	record := &tableRecord{ID: "dummyId", CreatedAt: "2020-01-01", Email: "stale@example.com", Version: 3}
+	if err := table.Update(context.Background(), record, update, IfExists("id")); err != nil {
+		t.Fatalf("Update failure %s", err.Error())
+	}
+	input := dynamoMock.UpdateItemInput
+	if aws.StringValue(input.UpdateExpression) != "SET #2 = :1, #1 = :2\n" {
+		t.Fatalf("Update must increment the version %q", aws.StringValue(input.UpdateExpression))
+	}
+	if aws.StringValue(input.ConditionExpression) != "(attribute_exists (#0)) AND (#1 = :0)" {
+		t.Fatalf("Update must be locked by the version %s", aws.StringValue(input.ConditionExpression))
+	}
+	if aws.StringValue(input.ReturnValues) != dynamodb.ReturnValueAllNew {
+		t.Fatalf("Update must return the updated item")
+	}
+	if record.Name != "updated" || record.Version != 4 || record.CreatedAt != "2020-01-01" {
+		t.Fatalf("Updated item must be set into the record %+v", record)
+	}
+	if record.Email != "" {
+		t.Fatalf("Attribute missing from the updated item must be cleared %+v", record)
+	}
+
+	// The update is reusable since the version action is not added to it
+	if err := table.Update(context.Background(), record, update); err != nil {
+		t.Fatalf("Update failure %s", err.Error())
+	}
+
+	delete(dynamoMock.ReturnAttributes, "name")
+	record.Name = "stale"
+	if err := table.Update(context.Background(), record, NewUpdate().Remove("name")); err != nil {
+		t.Fatalf("Update failure %s", err.Error())
+	}
+	if record.Name != "" || record.ID != "dummyId" || record.CreatedAt != "2020-01-01" {
+		t.Fatalf("Removed attribute must be cleared from the record %+v", record)
+	}
+
+	var returned tableRecord
+	if err := table.Update(context.Background(), record, update,
+		WithReturnValues(dynamodb.ReturnValueAllOld, &returned)); err == nil {
+		t.Fatalf("WithReturnValues must be error")
+	}
+	if err := table.Update(context.Background(), record, nil); err == nil {
+		t.Fatalf("Nil update must be error")
+	}
+}
+
+func TestTableDelete(t *testing.T) {
+	dynamoMock := &DynamoMock{}
+	table, _ := NewTable(New(dynamoMock), "records", tableRecord{})
+
+	if err := table.Delete(context.Background(), tableRecord{ID: "dummyId", CreatedAt: "2020-01-01"}); err != nil {
+		t.Fatalf("Delete failure %s", err.Error())
+	}
+	if dynamoMock.DeleteItemInput.ConditionExpression != nil {
+		t.Fatalf("Record without version must be deleted unconditionally")
+	}
+	if err := table.Delete(context.Background(), tableRecord{ID: "dummyId", CreatedAt: "2020-01-01", Version: 2}); err != nil {
+		t.Fatalf("Delete failure %s", err.Error())
+	}
+	input := dynamoMock.DeleteItemInput
+	if aws.StringValue(input.ConditionExpression) != "#0 = :0" || aws.StringValue(input.ExpressionAttributeValues[":0"].N) != "2" {
+		t.Fatalf("Delete must be locked by the version %v", input)
+	}
+}
+
+func TestTableQuery(t *testing.T) {
+	fixNow(t, 1000)
+	dynamoMock := &DynamoMock{Items: makeItems(3)}
+	table, _ := NewTable(New(dynamoMock), "records", tableRecord{})
+
+	records := []tableRecord{}
+	err := table.Query(context.Background(), "dummy@example.com", SortBeginsWith("2020-01"), &records,
+		WithIndex("by-email"), WithFilter(expression.Name("name").AttributeExists()))
+	if err == nil {
+		t.Fatalf("Sort condition on the index without sort key must be error")
+	}
+
+	err = table.Query(context.Background(), "dummyId", SortBetween("2020-01-01", "2020-12-31"), &records,
+		WithFilter(expression.Name("name").AttributeExists()))
+	if err != nil {
+		t.Fatalf("Query failure %s", err.Error())
+	}
+	if len(records) != 3 || records[0].ID != "id0" {
+		t.Fatalf("Invalid records %+v", records)
+	}
+	input := dynamoMock.QueryInputs[0]
+	if aws.StringValue(input.KeyConditionExpression) != "(#2 = :2) AND (#3 BETWEEN :3 AND :4)" {
+		t.Fatalf("Invalid key condition %s", aws.StringValue(input.KeyConditionExpression))
+	}
+	names := map[string]string{}
+	for placeholder, name := range input.ExpressionAttributeNames {
+		names[placeholder] = aws.StringValue(name)
+	}
+	if names["#2"] != "id" || names["#3"] != "created_at" {
+		t.Fatalf("Key condition must use the table keys %v", names)
+	}
+	if aws.StringValue(input.FilterExpression) != "(attribute_exists (#0)) AND ((attribute_not_exists (#1)) OR (#1 = :0) OR (#1 > :1))" ||
+		aws.StringValue(input.ExpressionAttributeValues[":1"].N) != "1000" {
+		t.Fatalf("Expired items must be filtered %s", aws.StringValue(input.FilterExpression))
+	}
+
+	dynamoMock.QueryInputs = nil
+	err = table.Query(context.Background(), "2020-01-01", SortEqual("dummyId"), &records, WithIndex("by-date"))
+	if err != nil {
+		t.Fatalf("Query failure %s", err.Error())
+	}
+	input = dynamoMock.QueryInputs[0]
+	if aws.StringValue(input.IndexName) != "by-date" {
+		t.Fatalf("Invalid index %s", aws.StringValue(input.IndexName))
+	}
+
+	if err := table.Query(context.Background(), "dummyId", nil, &records, WithIndex("missing")); err == nil {
+		t.Fatalf("Unknown index must be error")
+	}
+}
@@ -0,0 +1,215 @@
+package dynamo
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/pkg/errors"
+)
+
+// tagName is the struct tag of the wrapper options like `dynamo:"version"`
+const tagName = "dynamo"
+
+// The options of the dynamo tag. The key options may name the index like `dynamo:"pk=by-email"`
+// to declare the key of a global or local secondary index, and a field may have several options.
+const (
+	tagVersion   = "version"
+	tagPartition = "pk"
+	tagSort      = "sk"
+	tagTTL       = "ttl"
+)
+
+var (
+	unixTimeType = reflect.TypeOf(dynamodbattribute.UnixTime{})
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// taggedField is a struct field which has the dynamo tag
+type taggedField struct {
+	index []int
+	// name is the attribute name of the field as dynamodbattribute marshals it
+	name    string
+	options []string
+}
+
+// structInfo is the tagged fields of a struct type
+type structInfo struct {
+	version *taggedField
+	// partition and sort are the keys of the table
+	partition *taggedField
+	sort      *taggedField
+	ttl       *taggedField
+	indexes   map[string]*indexInfo
+}
+
+// indexInfo is the keys of a secondary index. The partition key of a local secondary index is nil.
+type indexInfo struct {
+	partition *taggedField
+	sort      *taggedField
+}
+
+var structInfos sync.Map
+
+// getStructInfo returns the tagged fields of the struct type, or of the struct the pointer type points
+func getStructInfo(t reflect.Type) (*structInfo, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return &structInfo{}, nil
+	}
+	if info, ok := structInfos.Load(t); ok {
+		return info.(*structInfo), nil
+	}
+	info := &structInfo{indexes: map[string]*indexInfo{}}
+	for _, field := range taggedFields(t, nil) {
+		fieldType := t.FieldByIndex(field.index).Type
+		for _, option := range field.options {
+			option, index := splitOption(option)
+			var target **taggedField
+			switch {
+			case option == tagVersion && index == "":
+				if !isInteger(fieldType) {
+					return nil, errors.New(fmt.Sprintf("Version field must be integer type=%s field=%s", t, field.name))
+				}
+				target = &info.version
+			case option == tagTTL && index == "":
+				if !isInteger(fieldType) && fieldType != unixTimeType && !(fieldType == timeType && isUnixTime(t, field)) {
+					return nil, errors.New(fmt.Sprintf(
+						"TTL field must be integer, UnixTime or time.Time tagged unixtime type=%s field=%s", t, field.name))
+				}
+				target = &info.ttl
+			case option == tagPartition || option == tagSort:
+				if !isKeyType(fieldType) {
+					return nil, errors.New(fmt.Sprintf(
+						"Key field must be string, number or binary type=%s field=%s", t, field.name))
+				}
+				target = info.keyField(option, index)
+			default:
+				return nil, errors.New(fmt.Sprintf("Unknown dynamo tag option type=%s field=%s option=%s",
+					t, field.name, option))
+			}
+			if *target != nil {
+				return nil, errors.New(fmt.Sprintf("Duplicated %s field type=%s field=%s", option, t, field.name))
+			}
+			*target = field
+		}
+	}
+	for name, index := range info.indexes {
+		if index.sort == nil && index.partition == nil {
+			return nil, errors.New(fmt.Sprintf("Index must have keys type=%s index=%s", t, name))
+		}
+		if index.partition == nil && info.partition == nil {
+			return nil, errors.New(fmt.Sprintf("Local secondary index needs the partition key type=%s index=%s", t, name))
+		}
+	}
+	structInfos.Store(t, info)
+	return info, nil
+}
+
+// keyField returns the destination of the key field of the table, or of the index when index is not empty
+func (info *structInfo) keyField(option string, index string) **taggedField {
+	if index == "" {
+		if option == tagPartition {
+			return &info.partition
+		}
+		return &info.sort
+	}
+	if info.indexes[index] == nil {
+		info.indexes[index] = &indexInfo{}
+	}
+	if option == tagPartition {
+		return &info.indexes[index].partition
+	}
+	return &info.indexes[index].sort
+}
+
+// splitOption splits the option like pk=by-email into the option and the index name
+func splitOption(option string) (string, string) {
+	parts := strings.SplitN(strings.TrimSpace(option), "=", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+func isInteger(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// isKeyType reports whether the type is marshaled into a string, number or binary
+func isKeyType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.Uint8
+	}
+	return isInteger(t)
+}
+
+// isUnixTime reports whether the field is tagged `dynamodbav:",unixtime"`
+func isUnixTime(t reflect.Type, field *taggedField) bool {
+	options := strings.Split(t.FieldByIndex(field.index).Tag.Get("dynamodbav"), ",")
+	for _, option := range options[1:] {
+		if option == "unixtime" {
+			return true
+		}
+	}
+	return false
+}
+
+// taggedFields returns the fields with the dynamo tag including the fields of embedded structs
+func taggedFields(t reflect.Type, index []int) []*taggedField {
+	fields := []*taggedField{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldIndex := append(append([]int{}, index...), i)
+		name, ok := attributeName(field)
+		if !ok {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Tag.Get("dynamodbav") == "" {
+			fields = append(fields, taggedFields(field.Type, fieldIndex)...)
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		tag := field.Tag.Get(tagName)
+		if tag == "" {
+			continue
+		}
+		fields = append(fields, &taggedField{
+			index:   fieldIndex,
+			name:    name,
+			options: strings.Split(tag, ","),
+		})
+	}
+	return fields
+}
+
+// attributeName returns the attribute name of the field by the dynamodbav tag, or the json tag when
+// the dynamodbav tag is absent, like dynamodbattribute. It returns false when the field is skipped by "-".
+func attributeName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("dynamodbav")
+	if tag == "" {
+		tag = field.Tag.Get("json")
+	}
+	if tag == "-" {
+		return "", false
+	}
+	if name := strings.Split(tag, ",")[0]; name != "" {
+		return name, true
+	}
+	return field.Name, true
+}
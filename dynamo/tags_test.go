@@ -0,0 +1,149 @@
+package dynamo
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+type testAudit struct {
+	Revision int `dynamodbav:"rev" dynamo:"version"`
+}
+
+type testEmbeddedRecord struct {
+	testAudit
+	ID string `json:"id"`
+}
+
+func TestGetStructInfo(t *testing.T) {
+	info, err := getStructInfo(reflect.TypeOf(&testEmbeddedRecord{}))
+	if err != nil {
+		t.Fatalf("Parse struct failure %s", err.Error())
+	}
+	if info.version == nil || info.version.name != "rev" || !reflect.DeepEqual(info.version.index, []int{0, 0}) {
+		t.Fatalf("Invalid version field %+v", info.version)
+	}
+
+	info, err = getStructInfo(reflect.TypeOf(TestRecord{}))
+	if err != nil || info.version != nil {
+		t.Fatalf("Struct without tags must have no version field info=%+v err=%v", info, err)
+	}
+	if info, err := getStructInfo(reflect.TypeOf("dummy")); err != nil || info.version != nil {
+		t.Fatalf("Non struct must have no version field info=%+v err=%v", info, err)
+	}
+}
+
+func TestGetStructInfoInvalid(t *testing.T) {
+	type stringVersion struct {
+		Version string `dynamo:"version"`
+	}
+	type duplicatedVersion struct {
+		Version  int `dynamo:"version"`
+		Revision int `dynamo:"version"`
+	}
+	for _, record := range []interface{}{stringVersion{}, duplicatedVersion{}} {
+		if _, err := getStructInfo(reflect.TypeOf(record)); err == nil {
+			t.Fatalf("Invalid version field must be error type=%T", record)
+		}
+	}
+}
+
+func TestAttributeName(t *testing.T) {
+	type record struct {
+		A string `dynamodbav:"a_name,omitempty" json:"ignored"`
+		B string `json:"b_name"`
+		C string
+		D string `dynamodbav:"-"`
+		E string `dynamodbav:",omitempty" json:"x"`
+	}
+	typ := reflect.TypeOf(record{})
+	expected := []string{"a_name", "b_name", "C"}
+	for i, name := range expected {
+		if actual, ok := attributeName(typ.Field(i)); !ok || actual != name {
+			t.Fatalf("Invalid attribute name expected=%s actual=%s", name, actual)
+		}
+	}
+	if _, ok := attributeName(typ.Field(3)); ok {
+		t.Fatalf("Skipped field must not have attribute name")
+	}
+
+	// the json tag is used only when the dynamodbav tag is absent as dynamodbattribute does
+	if actual, ok := attributeName(typ.Field(4)); !ok || actual != "E" {
+		t.Fatalf("Invalid attribute name expected=E actual=%s", actual)
+	}
+	item, err := dynamodbattribute.MarshalMap(record{E: "e"})
+	if err != nil {
+		t.Fatalf("Marshal failure %s", err.Error())
+	}
+	if _, ok := item["E"]; !ok {
+		t.Fatalf("Attribute name must match dynamodbattribute %+v", item)
+	}
+}
+
+type testKeyedRecord struct {
+	UserID    string                     `dynamodbav:"user_id" dynamo:"pk,pk=by-user"`
+	CreatedAt int64                      `dynamodbav:"created_at" dynamo:"sk,sk=by-date"`
+	Email     string                     `dynamodbav:"email" dynamo:"pk=by-email"`
+	ExpiresAt dynamodbattribute.UnixTime `dynamodbav:"expires_at" dynamo:"ttl"`
+}
+
+func TestGetStructInfoKeys(t *testing.T) {
+	info, err := getStructInfo(reflect.TypeOf(testKeyedRecord{}))
+	if err != nil {
+		t.Fatalf("Parse struct failure %s", err.Error())
+	}
+	if info.partition.name != "user_id" || info.sort.name != "created_at" || info.ttl.name != "expires_at" {
+		t.Fatalf("Invalid table keys %+v", info)
+	}
+	if index := info.indexes["by-user"]; index.partition.name != "user_id" || index.sort != nil {
+		t.Fatalf("Invalid index by-user %+v", index)
+	}
+	if index := info.indexes["by-email"]; index.partition.name != "email" || index.sort != nil {
+		t.Fatalf("Invalid index by-email %+v", index)
+	}
+	if index := info.indexes["by-date"]; index.partition != nil || index.sort.name != "created_at" {
+		t.Fatalf("Local secondary index must have only the sort key %+v", index)
+	}
+}
+
+func TestGetStructInfoInvalidKeys(t *testing.T) {
+	type duplicatedKey struct {
+		A string `dynamo:"pk"`
+		B string `dynamo:"pk"`
+	}
+	type boolKey struct {
+		A bool `dynamo:"pk"`
+	}
+	type stringTTL struct {
+		A string `dynamo:"pk"`
+		B string `dynamo:"ttl"`
+	}
+	type timeTTL struct {
+		A string    `dynamo:"pk"`
+		B time.Time `dynamo:"ttl"`
+	}
+	type unknownOption struct {
+		A string `dynamo:"partition"`
+	}
+	type localIndexWithoutTableKey struct {
+		A string `dynamo:"sk=by-date"`
+	}
+	records := []interface{}{
+		duplicatedKey{}, boolKey{}, stringTTL{}, timeTTL{}, unknownOption{}, localIndexWithoutTableKey{},
+	}
+	for _, record := range records {
+		if _, err := getStructInfo(reflect.TypeOf(record)); err == nil {
+			t.Fatalf("Invalid tags must be error type=%T", record)
+		}
+	}
+
+	type unixTimeTTL struct {
+		A string    `dynamo:"pk"`
+		B time.Time `dynamodbav:"b,unixtime" dynamo:"ttl"`
+	}
+	if _, err := getStructInfo(reflect.TypeOf(unixTimeTTL{})); err != nil {
+		t.Fatalf("Time tagged unixtime must be TTL %s", err.Error())
+	}
+}
@@ -0,0 +1,391 @@
+package dynamo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/pkg/errors"
+)
+
+const maxTransactItems = 100
+
+// Cancellation reason codes of the operations of a cancelled transaction
+const (
+	ReasonNone                   = "None"
+	ReasonConditionalCheckFailed = "ConditionalCheckFailed"
+	ReasonTransactionConflict    = "TransactionConflict"
+)
+
+// WriteTransaction builds the operations of TransactWriteItems, which succeed or fail all together.
+// The operations may be on different tables, but not on the same item.
+//
+//	tx := dynamo.NewWriteTransaction().
+//		Update("accounts", dynamo.NewKey("id", from), dynamo.NewUpdate().Decrement("balance", 100),
+//			dynamo.WithCondition(expression.Name("balance").GreaterThanEqual(expression.Value(100)))).
+//		Update("accounts", dynamo.NewKey("id", to), dynamo.NewUpdate().Increment("balance", 100)).
+//		Put("audits", &audit).
+//		WithToken(requestID)
+type WriteTransaction struct {
+	items      []*dynamodb.TransactWriteItem
+	operations []transactOperation
+	token      string
+	err        error
+}
+
+// transactOperation describes an operation of the transaction for the errors
+type transactOperation struct {
+	operation string
+	tableName string
+	lock      *versionLock
+	record    interface{}
+}
+
+// NewWriteTransaction returns the transaction without operations
+func NewWriteTransaction() *WriteTransaction {
+	return &WriteTransaction{}
+}
+
+// Put puts the record. The conditions of opts and the version field are checked as PutItem does.
+// WithReturnValues is not available as opts of the operations since the transaction returns no items.
+func (t *WriteTransaction) Put(tableName string, record interface{}, opts ...WriteOption) *WriteTransaction {
+	item, err := dynamodbattribute.MarshalMap(record)
+	if err != nil {
+		return t.fail(errors.Wrap(err, fmt.Sprintf("Marshal put item failure record=%+v", record)))
+	}
+	conf, err := newTransactWriteConfig(tableName, opts)
+	if err != nil {
+		return t.fail(err)
+	}
+	lock, err := newVersionLock(record)
+	if err != nil {
+		return t.fail(err)
+	}
+	if lock != nil {
+		lock.apply(item)
+		conf.conditions = append(conf.conditions, lock.condition())
+	}
+	expr, err := conf.expression(nil)
+	if err != nil {
+		return t.fail(err)
+	}
+	put := &dynamodb.Put{
+		TableName: aws.String(tableName),
+		Item:      item,
+	}
+	if expr != nil {
+		put.ConditionExpression = expr.Condition()
+		put.ExpressionAttributeNames = expr.Names()
+		put.ExpressionAttributeValues = expr.Values()
+	}
+	return t.add(&dynamodb.TransactWriteItem{Put: put},
+		transactOperation{operation: "Put", tableName: tableName, lock: lock, record: record})
+}
+
+// Update updates the item of the key by the actions of the update. See GetItemWithKey about key.
+func (t *WriteTransaction) Update(tableName string, key interface{}, update *Update, opts ...WriteOption) *WriteTransaction {
+	keyValues, err := marshalKey(key)
+	if err != nil {
+		return t.fail(err)
+	}
+	builder, err := update.build()
+	if err != nil {
+		return t.fail(errors.Wrap(err, fmt.Sprintf("Build update failure table=%s", tableName)))
+	}
+	conf, err := newTransactWriteConfig(tableName, opts)
+	if err != nil {
+		return t.fail(err)
+	}
+	expr, err := conf.expression(&builder)
+	if err != nil {
+		return t.fail(err)
+	}
+	return t.add(&dynamodb.TransactWriteItem{Update: &dynamodb.Update{
+		TableName:                 aws.String(tableName),
+		Key:                       keyValues,
+		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}}, transactOperation{operation: "Update", tableName: tableName})
+}
+
+// Delete deletes the item of the key. See GetItemWithKey about key.
+func (t *WriteTransaction) Delete(tableName string, key interface{}, opts ...WriteOption) *WriteTransaction {
+	keyValues, err := marshalKey(key)
+	if err != nil {
+		return t.fail(err)
+	}
+	conf, err := newTransactWriteConfig(tableName, opts)
+	if err != nil {
+		return t.fail(err)
+	}
+	expr, err := conf.expression(nil)
+	if err != nil {
+		return t.fail(err)
+	}
+	del := &dynamodb.Delete{
+		TableName: aws.String(tableName),
+		Key:       keyValues,
+	}
+	if expr != nil {
+		del.ConditionExpression = expr.Condition()
+		del.ExpressionAttributeNames = expr.Names()
+		del.ExpressionAttributeValues = expr.Values()
+	}
+	return t.add(&dynamodb.TransactWriteItem{Delete: del},
+		transactOperation{operation: "Delete", tableName: tableName})
+}
+
+// ConditionCheck cancels the transaction unless the item of the key satisfies the condition,
+// without writing the item. See GetItemWithKey about key.
+func (t *WriteTransaction) ConditionCheck(
+	tableName string, key interface{}, condition expression.ConditionBuilder) *WriteTransaction {
+
+	keyValues, err := marshalKey(key)
+	if err != nil {
+		return t.fail(err)
+	}
+	expr, err := newWriteConfig([]WriteOption{WithCondition(condition)}).expression(nil)
+	if err != nil {
+		return t.fail(err)
+	}
+	return t.add(&dynamodb.TransactWriteItem{ConditionCheck: &dynamodb.ConditionCheck{
+		TableName:                 aws.String(tableName),
+		Key:                       keyValues,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}}, transactOperation{operation: "ConditionCheck", tableName: tableName})
+}
+
+// WithToken makes the transaction idempotent by the client request token. Retries of the transaction
+// with the same token within 10 minutes succeed without writing the items again.
+func (t *WriteTransaction) WithToken(token string) *WriteTransaction {
+	t.token = token
+	return t
+}
+
+func (t *WriteTransaction) add(item *dynamodb.TransactWriteItem, operation transactOperation) *WriteTransaction {
+	t.items = append(t.items, item)
+	t.operations = append(t.operations, operation)
+	return t
+}
+
+// newTransactWriteConfig returns the config of the options, which must not have WithReturnValues
+// because the destination would never be filled
+func newTransactWriteConfig(tableName string, opts []WriteOption) (writeConfig, error) {
+	conf := newWriteConfig(opts)
+	if conf.returnValues != "" {
+		return conf, errors.New(fmt.Sprintf("Transaction does not support WithReturnValues table=%s", tableName))
+	}
+	return conf, nil
+}
+
+func (t *WriteTransaction) fail(err error) *WriteTransaction {
+	if t.err == nil {
+		t.err = errors.Wrap(err, fmt.Sprintf("Invalid transaction operation index=%d", len(t.items)))
+	}
+	return t
+}
+
+// GetTransaction builds the gets of TransactGetItems, which read the items at the same point in time
+type GetTransaction struct {
+	items  []*dynamodb.TransactGetItem
+	tables []string
+	outs   []interface{}
+	found  []bool
+	err    error
+}
+
+// NewGetTransaction returns the transaction without gets
+func NewGetTransaction() *GetTransaction {
+	return &GetTransaction{}
+}
+
+// Get reads the item of the key into out, which must be a pointer. See GetItemWithKey about key.
+// WithProjection is available as opts, and the other options are error because the transaction
+// always reads consistently.
+func (g *GetTransaction) Get(tableName string, key interface{}, out interface{}, opts ...ReadOption) *GetTransaction {
+	keyValues, err := marshalKey(key)
+	if err != nil {
+		return g.fail(err)
+	}
+	conf := newReadConfig(opts)
+	if err := conf.checkOptions("TransactGetItems", "WithProjection"); err != nil {
+		return g.fail(errors.Wrap(err, fmt.Sprintf("Invalid read option table=%s", tableName)))
+	}
+	input, err := conf.getItemInput(tableName, keyValues)
+	if err != nil {
+		return g.fail(err)
+	}
+	g.items = append(g.items, &dynamodb.TransactGetItem{Get: &dynamodb.Get{
+		TableName:                input.TableName,
+		Key:                      input.Key,
+		ProjectionExpression:     input.ProjectionExpression,
+		ExpressionAttributeNames: input.ExpressionAttributeNames,
+	}})
+	g.tables = append(g.tables, tableName)
+	g.outs = append(g.outs, out)
+	return g
+}
+
+func (g *GetTransaction) fail(err error) *GetTransaction {
+	if g.err == nil {
+		g.err = errors.Wrap(err, fmt.Sprintf("Invalid transaction get index=%d", len(g.items)))
+	}
+	return g
+}
+
+// Found reports whether the item of the index-th get existed. The out of a missing item is left as is.
+func (g *GetTransaction) Found(index int) bool {
+	return index < len(g.found) && g.found[index]
+}
+
+// OperationError is the reason why an operation of a transaction cancelled it
+type OperationError struct {
+	// Index is the index of the operation in the transaction
+	Index     int
+	Operation string
+	Table     string
+	// Code is the cancellation reason code like ReasonConditionalCheckFailed
+	Code    string
+	Message string
+}
+
+func (e *OperationError) Error() string {
+	return fmt.Sprintf("Transaction operation failure index=%d operation=%s table=%s code=%s message=%s",
+		e.Index, e.Operation, e.Table, e.Code, e.Message)
+}
+
+// Unwrap returns ErrConditionFailed or ErrTransactionConflict for the codes of them
+func (e *OperationError) Unwrap() error {
+	switch e.Code {
+	case ReasonConditionalCheckFailed:
+		return ErrConditionFailed
+	case ReasonTransactionConflict:
+		return ErrTransactionConflict
+	}
+	return nil
+}
+
+// TransactionCanceledError is returned when dynamodb cancels the transaction.
+// errors.Is reports ErrConditionFailed or ErrTransactionConflict when any operation failed by it.
+type TransactionCanceledError struct {
+	// Failures is the operations which cancelled the transaction
+	Failures []*OperationError
+	Err      error
+}
+
+func (e *TransactionCanceledError) Error() string {
+	if len(e.Failures) == 0 {
+		return fmt.Sprintf("Transaction canceled %v", e.Err)
+	}
+	return fmt.Sprintf("Transaction canceled failures=%d first=%s", len(e.Failures), e.Failures[0].Error())
+}
+
+func (e *TransactionCanceledError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether any operation failed by the target
+func (e *TransactionCanceledError) Is(target error) bool {
+	for _, failure := range e.Failures {
+		if failure.Unwrap() == target {
+			return true
+		}
+	}
+	return false
+}
+
+// newTransactionCanceledError decodes the cancellation reasons, which are in the order of the operations
+func newTransactionCanceledError(
+	err *dynamodb.TransactionCanceledException, operations []transactOperation) *TransactionCanceledError {
+
+	canceled := &TransactionCanceledError{Err: err}
+	for i, reason := range err.CancellationReasons {
+		code := aws.StringValue(reason.Code)
+		if code == "" || code == ReasonNone {
+			continue
+		}
+		failure := &OperationError{Index: i, Code: code, Message: aws.StringValue(reason.Message)}
+		if i < len(operations) {
+			failure.Operation = operations[i].operation
+			failure.Table = operations[i].tableName
+		}
+		canceled.Failures = append(canceled.Failures, failure)
+	}
+	return canceled
+}
+
+// TransactWrite runs the operations of the transaction all or nothing. It returns *TransactionCanceledError
+// when dynamodb cancels the transaction. The versions of the records put with the version field are
+// set into the records which are pointers after the transaction succeeded.
+func (s *wrapperDynamo) TransactWrite(ctx context.Context, tx *WriteTransaction) (*dynamodb.TransactWriteItemsOutput, error) {
+	if tx.err != nil {
+		return nil, tx.err
+	}
+	if len(tx.items) == 0 || len(tx.items) > maxTransactItems {
+		return nil, errors.New(fmt.Sprintf("Transaction must have 1 to %d operations operations=%d",
+			maxTransactItems, len(tx.items)))
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	input := &dynamodb.TransactWriteItemsInput{TransactItems: tx.items}
+	if tx.token != "" {
+		input.ClientRequestToken = aws.String(tx.token)
+	}
+	output, err := s.Client.TransactWriteItems(input)
+	if err != nil {
+		if canceled, ok := err.(*dynamodb.TransactionCanceledException); ok {
+			return nil, newTransactionCanceledError(canceled, tx.operations)
+		}
+		return nil, errors.Wrap(err, fmt.Sprintf("Transact write items failure operations=%d", len(tx.items)))
+	}
+	for _, operation := range tx.operations {
+		if operation.lock != nil {
+			operation.lock.commit(operation.record)
+		}
+	}
+	return output, nil
+}
+
+// TransactGet reads the items of the gets at the same point in time and unmarshals them into the outs.
+// It returns *TransactionCanceledError when dynamodb cancels the transaction.
+func (s *wrapperDynamo) TransactGet(ctx context.Context, get *GetTransaction) error {
+	if get.err != nil {
+		return get.err
+	}
+	if len(get.items) == 0 || len(get.items) > maxTransactItems {
+		return errors.New(fmt.Sprintf("Transaction must have 1 to %d gets gets=%d", maxTransactItems, len(get.items)))
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	output, err := s.Client.TransactGetItems(&dynamodb.TransactGetItemsInput{TransactItems: get.items})
+	if err != nil {
+		if canceled, ok := err.(*dynamodb.TransactionCanceledException); ok {
+			operations := make([]transactOperation, len(get.tables))
+			for i, tableName := range get.tables {
+				operations[i] = transactOperation{operation: "Get", tableName: tableName}
+			}
+			return newTransactionCanceledError(canceled, operations)
+		}
+		return errors.Wrap(err, fmt.Sprintf("Transact get items failure gets=%d", len(get.items)))
+	}
+	get.found = make([]bool, len(get.items))
+	for i, response := range output.Responses {
+		if i >= len(get.outs) || response == nil || len(response.Item) == 0 {
+			continue
+		}
+		if err := dynamodbattribute.UnmarshalMap(response.Item, get.outs[i]); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("Unmarshal item failure index=%d table=%s", i, get.tables[i]))
+		}
+		get.found[i] = true
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package dynamo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/pkg/errors"
+)
+
+func TestTransactWrite(t *testing.T) {
+	dynamoMock := &DynamoMock{}
+	dynamoDBClient := New(dynamoMock)
+
+	record := &versionedRecord{ID: "audit", Version: 2}
+	tx := NewWriteTransaction().
+		Update("accounts", NewKey("id", "from"), NewUpdate().Decrement("balance", 100),
+			WithCondition(expression.Name("balance").GreaterThanEqual(expression.Value(100)))).
+		Update("accounts", NewKey("id", "to"), NewUpdate().Increment("balance", 100)).
+		Put("audits", record).
+		Delete("pending", NewKey("id", "transfer"), IfExists("id")).
+		ConditionCheck("users", NewKey("id", "owner"), expression.Name("active").Equal(expression.Value(true))).
+		WithToken("dummyToken")
+	if _, err := dynamoDBClient.TransactWrite(context.Background(), tx); err != nil {
+		t.Fatalf("Transact write failure %s", err.Error())
+	}
+	input := dynamoMock.TransactWriteInput
+	if len(input.TransactItems) != 5 || aws.StringValue(input.ClientRequestToken) != "dummyToken" {
+		t.Fatalf("Invalid transaction %v", input)
+	}
+	update := input.TransactItems[0].Update
+	if aws.StringValue(update.TableName) != "accounts" || aws.StringValue(update.ConditionExpression) != "#0 >= :0" {
+		t.Fatalf("Invalid update %v", update)
+	}
+	put := input.TransactItems[2].Put
+	if aws.StringValue(put.Item["version"].N) != "3" || aws.StringValue(put.ConditionExpression) != "#0 = :0" {
+		t.Fatalf("Put must be locked by the version %v", put)
+	}
+	if record.Version != 3 {
+		t.Fatalf("Version must be set after the transaction version=%d", record.Version)
+	}
+	if aws.StringValue(input.TransactItems[3].Delete.ConditionExpression) != "attribute_exists (#0)" {
+		t.Fatalf("Invalid delete %v", input.TransactItems[3].Delete)
+	}
+	if aws.StringValue(input.TransactItems[4].ConditionCheck.ConditionExpression) != "#0 = :0" {
+		t.Fatalf("Invalid condition check %v", input.TransactItems[4].ConditionCheck)
+	}
+}
+
+func TestTransactWriteCanceled(t *testing.T) {
+	dynamoMock := &DynamoMock{}
+	dynamoDBClient := New(dynamoMock)
+
+	dynamoMock.WriteErr = &dynamodb.TransactionCanceledException{
+		Message_: aws.String("dummy canceled"),
+		CancellationReasons: []*dynamodb.CancellationReason{
+			{Code: aws.String("None")},
+			{Code: aws.String("ConditionalCheckFailed"), Message: aws.String("dummy condition")},
+		},
+	}
+	record := &versionedRecord{ID: "dummyId", Version: 2}
+	tx := NewWriteTransaction().
+		Delete("pending", NewKey("id", "dummyId")).
+		Put("records", record)
+	_, err := dynamoDBClient.TransactWrite(context.Background(), tx)
+	canceled, ok := err.(*TransactionCanceledError)
+	if !ok || len(canceled.Failures) != 1 {
+		t.Fatalf("Canceled transaction must be TransactionCanceledError err=%v", err)
+	}
+	failure := canceled.Failures[0]
+	if failure.Index != 1 || failure.Operation != "Put" || failure.Table != "records" || failure.Code != ReasonConditionalCheckFailed {
+		t.Fatalf("Invalid failure %+v", failure)
+	}
+	if !errors.Is(err, ErrConditionFailed) || errors.Is(err, ErrTransactionConflict) {
+		t.Fatalf("Failure must be ErrConditionFailed")
+	}
+	if record.Version != 2 {
+		t.Fatalf("Version must not be set after the cancel version=%d", record.Version)
+	}
+
+	dynamoMock.WriteErr = awserr.New(dynamodb.ErrCodeIdempotentParameterMismatchException, "dummy mismatch", nil)
+	if _, err := dynamoDBClient.TransactWrite(context.Background(), tx); err == nil {
+		t.Fatalf("Transact write error must be returned")
+	} else if _, ok := err.(*TransactionCanceledError); ok {
+		t.Fatalf("Other errors must not be TransactionCanceledError")
+	}
+}
+
+func TestTransactWriteInvalid(t *testing.T) {
+	dynamoDBClient := New(&DynamoMock{})
+
+	if _, err := dynamoDBClient.TransactWrite(context.Background(), NewWriteTransaction()); err == nil {
+		t.Fatalf("Empty transaction must be error")
+	}
+	tx := NewWriteTransaction().
+		Update("accounts", NewKey("id", "from"), NewUpdate()).
+		Delete("accounts", NewKey("id", []int{1}))
+	if _, err := dynamoDBClient.TransactWrite(context.Background(), tx); err == nil {
+		t.Fatalf("Invalid operation must be error")
+	}
+}
+
+func TestTransactWriteReturnValues(t *testing.T) {
+	dynamoMock := &DynamoMock{}
+	dynamoDBClient := New(dynamoMock)
+
+	var old TestRecord
+	returnValues := WithReturnValues(dynamodb.ReturnValueAllOld, &old)
+	for _, tx := range []*WriteTransaction{
+		NewWriteTransaction().Put("records", TestRecord{ID: "id0"}, returnValues),
+		NewWriteTransaction().Update("records", NewKey("id", "id0"), NewUpdate().Set("desc_text", "text"), returnValues),
+		NewWriteTransaction().Delete("records", NewKey("id", "id0"), returnValues),
+	} {
+		if _, err := dynamoDBClient.TransactWrite(context.Background(), tx); err == nil {
+			t.Fatalf("WithReturnValues must be error")
+		}
+	}
+	if dynamoMock.TransactWriteInput != nil {
+		t.Fatalf("Transaction must not run with WithReturnValues")
+	}
+}
+
+func TestTransactGet(t *testing.T) {
+	dynamoMock := &DynamoMock{Items: makeItems(1)}
+	dynamoDBClient := New(dynamoMock)
+
+	var first, second TestRecord
+	get := NewGetTransaction().
+		Get("records", NewKey("id", "id0"), &first, WithProjection("id", "desc_text")).
+		Get("others", NewKey("id", "missing"), &second)
+	if err := dynamoDBClient.TransactGet(context.Background(), get); err != nil {
+		t.Fatalf("Transact get failure %s", err.Error())
+	}
+	if first.ID != "id0" || !get.Found(0) {
+		t.Fatalf("Invalid first record %+v", first)
+	}
+	if second.ID != "" || get.Found(1) {
+		t.Fatalf("Missing item must not be found %+v", second)
+	}
+	items := dynamoMock.TransactGetInput.TransactItems
+	if aws.StringValue(items[1].Get.TableName) != "others" || aws.StringValue(items[0].Get.ProjectionExpression) != "#0, #1" {
+		t.Fatalf("Invalid gets %v", items)
+	}
+
+	dynamoMock.ReadErr = &dynamodb.TransactionCanceledException{
+		CancellationReasons: []*dynamodb.CancellationReason{
+			{Code: aws.String("TransactionConflict")},
+			{Code: aws.String("None")},
+		},
+	}
+	err := dynamoDBClient.TransactGet(context.Background(), get)
+	if !errors.Is(err, ErrTransactionConflict) {
+		t.Fatalf("Conflict must be ErrTransactionConflict err=%v", err)
+	}
+}
+
+func TestTransactGetInvalidOptions(t *testing.T) {
+	dynamoMock := &DynamoMock{Items: makeItems(1)}
+	dynamoDBClient := New(dynamoMock)
+
+	var record TestRecord
+	for _, opt := range []ReadOption{
+		WithConsistentRead(),
+		WithFilter(expression.Name("id").Equal(expression.Value("id0"))),
+		WithIndex("by-text"),
+	} {
+		get := NewGetTransaction().Get("records", NewKey("id", "id0"), &record, opt)
+		if err := dynamoDBClient.TransactGet(context.Background(), get); err == nil {
+			t.Fatalf("Unsupported read option must be error")
+		}
+	}
+	if dynamoMock.TransactGetInput != nil {
+		t.Fatalf("Transaction must not run with unsupported option")
+	}
+}
@@ -0,0 +1,224 @@
+package dynamo
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/pkg/errors"
+)
+
+// Update builds the actions of an update expression. Attribute names are document paths like
+// info.tags[0], and names and values are always passed by placeholders.
+//
+//	update := dynamo.NewUpdate().
+//		Set("status", "done").
+//		Increment("retries", 1).
+//		Append("history", []string{"done"}).
+//		Remove("lease")
+type Update struct {
+	// actions are replayed on a new builder by build, since the builders share the operations when copied
+	actions []func(expression.UpdateBuilder) expression.UpdateBuilder
+	err     error
+}
+
+// NewUpdate returns the update without actions
+func NewUpdate() *Update {
+	return &Update{}
+}
+
+// Set sets the attribute to the value
+func (u *Update) Set(name string, value interface{}) *Update {
+	return u.set(name, expression.Value(value))
+}
+
+// SetIfNotExists sets the attribute to the value only when the attribute does not exist
+func (u *Update) SetIfNotExists(name string, value interface{}) *Update {
+	return u.set(name, expression.Name(name).IfNotExists(expression.Value(value)))
+}
+
+// Increment adds delta to the number attribute as an atomic counter. A missing attribute counts from zero.
+func (u *Update) Increment(name string, delta interface{}) *Update {
+	return u.set(name, expression.Plus(expression.Name(name).IfNotExists(expression.Value(0)), expression.Value(delta)))
+}
+
+// Decrement subtracts delta from the number attribute as an atomic counter. A missing attribute counts from zero.
+func (u *Update) Decrement(name string, delta interface{}) *Update {
+	return u.set(name, expression.Minus(expression.Name(name).IfNotExists(expression.Value(0)), expression.Value(delta)))
+}
+
+// Append appends the elements of values, which must be a slice, to the end of the list attribute.
+// A missing attribute is created.
+func (u *Update) Append(name string, values interface{}) *Update {
+	list, err := listValue(values)
+	if err != nil {
+		return u.fail(err)
+	}
+	return u.set(name, expression.ListAppend(
+		expression.Name(name).IfNotExists(expression.Value(emptyList())), expression.Value(list)))
+}
+
+// Prepend inserts the elements of values, which must be a slice, to the head of the list attribute.
+// A missing attribute is created.
+func (u *Update) Prepend(name string, values interface{}) *Update {
+	list, err := listValue(values)
+	if err != nil {
+		return u.fail(err)
+	}
+	return u.set(name, expression.ListAppend(
+		expression.Value(list), expression.Name(name).IfNotExists(expression.Value(emptyList()))))
+}
+
+// Add adds the number to the number attribute, or the elements to the set attribute by ADD.
+// Slices of strings, numbers and []byte are added as string, number and binary sets.
+func (u *Update) Add(name string, value interface{}) *Update {
+	av, err := setValue(value)
+	if err != nil {
+		return u.fail(err)
+	}
+	return u.add(func(builder expression.UpdateBuilder) expression.UpdateBuilder {
+		return builder.Add(expression.Name(name), expression.Value(av))
+	})
+}
+
+// Delete deletes the elements from the set attribute by DELETE. See Add about the sets.
+func (u *Update) Delete(name string, value interface{}) *Update {
+	av, err := setValue(value)
+	if err != nil {
+		return u.fail(err)
+	}
+	return u.add(func(builder expression.UpdateBuilder) expression.UpdateBuilder {
+		return builder.Delete(expression.Name(name), expression.Value(av))
+	})
+}
+
+// Remove removes the attributes
+func (u *Update) Remove(names ...string) *Update {
+	for _, name := range names {
+		name := name
+		u.add(func(builder expression.UpdateBuilder) expression.UpdateBuilder {
+			return builder.Remove(expression.Name(name))
+		})
+	}
+	return u
+}
+
+func (u *Update) set(name string, operand expression.OperandBuilder) *Update {
+	return u.add(func(builder expression.UpdateBuilder) expression.UpdateBuilder {
+		return builder.Set(expression.Name(name), operand)
+	})
+}
+
+func (u *Update) add(action func(expression.UpdateBuilder) expression.UpdateBuilder) *Update {
+	u.actions = append(u.actions, action)
+	return u
+}
+
+func (u *Update) fail(err error) *Update {
+	if u.err == nil {
+		u.err = err
+	}
+	return u
+}
+
+// build returns a new update builder of the actions, or the first error of the actions
+func (u *Update) build() (expression.UpdateBuilder, error) {
+	builder := expression.UpdateBuilder{}
+	if u == nil {
+		return builder, errors.New("Update must not be nil")
+	}
+	if u.err != nil {
+		return builder, u.err
+	}
+	if len(u.actions) == 0 {
+		return builder, errors.New("Update must have at least one action")
+	}
+	for _, action := range u.actions {
+		builder = action(builder)
+	}
+	return builder, nil
+}
+
+// ApplyUpdate updates the item of the key by the actions of the update.
+// See UpdateItem about key and opts, and WithReturnValues to get the updated item.
+func (s *wrapperDynamo) ApplyUpdate(
+	tableName string, key interface{}, update *Update, opts ...WriteOption) (*dynamodb.UpdateItemOutput, error) {
+
+	builder, err := update.build()
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("Build update failure table=%s", tableName))
+	}
+	return s.UpdateItem(tableName, key, builder, opts...)
+}
+
+func emptyList() *dynamodb.AttributeValue {
+	return &dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{}}
+}
+
+// listValue marshals the slice into a list
+func listValue(values interface{}) (*dynamodb.AttributeValue, error) {
+	v := reflect.ValueOf(values)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, errors.New(fmt.Sprintf("List values must be slice values=%T", values))
+	}
+	list := emptyList()
+	for i := 0; i < v.Len(); i++ {
+		av, err := marshalValue(v.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		list.L = append(list.L, av)
+	}
+	return list, nil
+}
+
+// setValue marshals slices of strings, numbers and []byte into sets, and the other values as is
+func setValue(value interface{}) (*dynamodb.AttributeValue, error) {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice || v.Type().Elem().Kind() == reflect.Uint8 {
+		return marshalValue(value)
+	}
+	if v.Len() == 0 {
+		return nil, errors.New("Set must have at least one element")
+	}
+	av := &dynamodb.AttributeValue{}
+	for i := 0; i < v.Len(); i++ {
+		elem := v.Index(i)
+		if elem.Kind() == reflect.Interface {
+			elem = elem.Elem()
+		}
+		switch elem.Kind() {
+		case reflect.String:
+			av.SS = append(av.SS, aws.String(elem.String()))
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			av.NS = append(av.NS, aws.String(strconv.FormatInt(elem.Int(), 10)))
+		case reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			av.NS = append(av.NS, aws.String(strconv.FormatUint(elem.Uint(), 10)))
+		case reflect.Float32, reflect.Float64:
+			av.NS = append(av.NS, aws.String(strconv.FormatFloat(elem.Float(), 'f', -1, 64)))
+		case reflect.Slice:
+			if elem.Type().Elem().Kind() != reflect.Uint8 {
+				return nil, errors.New(fmt.Sprintf("Unsupported set element element=%v", v.Index(i).Interface()))
+			}
+			av.BS = append(av.BS, elem.Bytes())
+		default:
+			return nil, errors.New(fmt.Sprintf("Unsupported set element element=%v", v.Index(i).Interface()))
+		}
+	}
+	if len(av.SS) != v.Len() && len(av.NS) != v.Len() && len(av.BS) != v.Len() {
+		return nil, errors.New(fmt.Sprintf("Set elements must have the same type values=%v", value))
+	}
+	return av, nil
+}
+
+func marshalValue(value interface{}) (*dynamodb.AttributeValue, error) {
+	av, err := dynamodbattribute.Marshal(value)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("Marshal value failure value=%+v", value))
+	}
+	return av, nil
+}
@@ -0,0 +1,116 @@
+package dynamo
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+func TestApplyUpdate(t *testing.T) {
+	dynamoMock := &DynamoMock{}
+	dynamoDBClient := New(dynamoMock)
+
+	update := NewUpdate().
+		Set("name", "dummy").
+		SetIfNotExists("created_at", "2020-01-01").
+		Increment("count", 1).
+		Decrement("stock", 2).
+		Append("history", []string{"a"}).
+		Prepend("recent", []string{"b"}).
+		Add("tags", []string{"x", "y"}).
+		Delete("codes", []int{1}).
+		Remove("lease", "info.tmp")
+	if _, err := dynamoDBClient.ApplyUpdate("test-table", NewKey("id", "dummyId"), update); err != nil {
+		t.Fatalf("Apply update failure %s", err.Error())
+	}
+	input := dynamoMock.UpdateItemInput
+	expected := "ADD #0 :0\nDELETE #1 :1\nREMOVE #2, #3.#4\n" +
+		"SET #5 = :2, #6 = if_not_exists(#6, :3), #7 = if_not_exists(#7, :4) + :5, #8 = if_not_exists(#8, :6) - :7, " +
+		"#9 = list_append(if_not_exists(#9, :8), :9), #10 = list_append(:10, if_not_exists(#10, :11))\n"
+	if aws.StringValue(input.UpdateExpression) != expected {
+		t.Fatalf("Invalid update expression %q", aws.StringValue(input.UpdateExpression))
+	}
+	names := map[string]string{}
+	for placeholder, name := range input.ExpressionAttributeNames {
+		names[placeholder] = aws.StringValue(name)
+	}
+	if names["#0"] != "tags" || names["#3"] != "info" || names["#4"] != "tmp" || names["#5"] != "name" {
+		t.Fatalf("Invalid placeholder names %v", names)
+	}
+	values := input.ExpressionAttributeValues
+	if len(values[":0"].SS) != 2 || aws.StringValue(values[":1"].NS[0]) != "1" {
+		t.Fatalf("Slices must be added and deleted as sets %v %v", values[":0"], values[":1"])
+	}
+	if values[":8"].L == nil || len(values[":8"].L) != 0 || aws.StringValue(values[":9"].L[0].S) != "a" {
+		t.Fatalf("Append must default to empty list %v %v", values[":8"], values[":9"])
+	}
+}
+
+func TestApplyUpdateReturnValues(t *testing.T) {
+	dynamoMock := &DynamoMock{
+		ReturnAttributes: map[string]*dynamodb.AttributeValue{
+			"id":        {S: aws.String("dummyId")},
+			"desc_text": {S: aws.String("updated")},
+		},
+	}
+	dynamoDBClient := New(dynamoMock)
+
+	var record TestRecord
+	_, err := dynamoDBClient.ApplyUpdate("test-table", NewKey("id", "dummyId"), NewUpdate().Set("desc_text", "updated"),
+		WithCondition(expression.AttributeExists(expression.Name("id"))),
+		WithReturnValues(dynamodb.ReturnValueAllNew, &record))
+	if err != nil {
+		t.Fatalf("Apply update failure %s", err.Error())
+	}
+	input := dynamoMock.UpdateItemInput
+	if aws.StringValue(input.ReturnValues) != dynamodb.ReturnValueAllNew {
+		t.Fatalf("Invalid return values %s", aws.StringValue(input.ReturnValues))
+	}
+	if aws.StringValue(input.ConditionExpression) != "attribute_exists (#0)" {
+		t.Fatalf("Invalid condition %s", aws.StringValue(input.ConditionExpression))
+	}
+	if record.ID != "dummyId" || record.DescText != "updated" {
+		t.Fatalf("Returned attributes must be unmarshaled %+v", record)
+	}
+
+	var old TestRecord
+	if _, err := dynamoDBClient.DeleteItem("test-table", NewKey("id", "dummyId"),
+		WithReturnValues(dynamodb.ReturnValueAllOld, &old)); err != nil {
+		t.Fatalf("Delete item failure %s", err.Error())
+	}
+	if old.DescText != "updated" {
+		t.Fatalf("Deleted item must be unmarshaled %+v", old)
+	}
+}
+
+func TestInvalidUpdate(t *testing.T) {
+	dynamoDBClient := New(&DynamoMock{})
+	for _, update := range []*Update{
+		NewUpdate(),
+		NewUpdate().Append("history", "not list"),
+		NewUpdate().Add("tags", []string{}),
+		NewUpdate().Add("tags", []interface{}{"a", 1}),
+		NewUpdate().Delete("tags", []bool{true}),
+	} {
+		if _, err := dynamoDBClient.ApplyUpdate("test-table", NewKey("id", "dummyId"), update); err == nil {
+			t.Fatalf("Invalid update must be error")
+		}
+	}
+}
+
+func TestSetValue(t *testing.T) {
+	av, err := setValue([][]byte{{0x01}, {0x02}})
+	if err != nil || len(av.BS) != 2 {
+		t.Fatalf("Binary slices must be binary set %v err=%v", av, err)
+	}
+	av, err = setValue([]float64{1.5})
+	if err != nil || aws.StringValue(av.NS[0]) != "1.5" {
+		t.Fatalf("Float slices must be number set %v err=%v", av, err)
+	}
+	av, err = setValue(5)
+	if err != nil || aws.StringValue(av.N) != "5" {
+		t.Fatalf("Number must be added as is %v err=%v", av, err)
+	}
+}
@@ -1,6 +1,10 @@
 module github.com/nuts300/aws-go-wrapper
 
+go 1.19
+
 require (
-	github.com/aws/aws-sdk-go v1.26.7
-	github.com/pkg/errors v0.8.1
+	github.com/aws/aws-sdk-go v1.55.8
+	github.com/pkg/errors v0.9.1
 )
+
+require github.com/jmespath/go-jmespath v0.4.0 // indirect